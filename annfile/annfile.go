@@ -0,0 +1,156 @@
+package annfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sap-go/keystore"
+	"sap-go/protocol"
+)
+
+// maxLine is the longest record line Read accepts.
+const maxLine = 1 << 16
+
+// ErrFormat is returned when a line is not a valid announcement record.
+var ErrFormat = errors.New("invalid announcement record")
+
+// Record is one announcement in a JSON Lines announcement file, tagged with
+// the protocol it was sent with.
+type Record struct {
+	Protocol  string           `json:"protocol"`
+	Curve     string           `json:"curve"`
+	Ephemeral keystore.Bytes   `json:"ephemeral"`
+	ViewTag   uint8            `json:"viewTag"`
+	Address   protocol.Address `json:"address"`
+}
+
+// NewRecord tags ann with p.
+func NewRecord(p protocol.Protocol, ann protocol.Announcement) Record {
+	return Record{Protocol: p.Name(), Curve: p.Curve(), Ephemeral: ann.Ephemeral, ViewTag: ann.ViewTag, Address: ann.Address}
+}
+
+// Announcement returns the announcement of r.
+func (r Record) Announcement() protocol.Announcement {
+	return protocol.Announcement{Ephemeral: r.Ephemeral, ViewTag: r.ViewTag, Address: r.Address}
+}
+
+// Decode looks up the protocol of r and decodes its ephemeral key.
+func (r Record) Decode() (protocol.Protocol, protocol.Decoded, error) {
+	p, err := protocol.Lookup(r.Protocol, r.Curve)
+	if err != nil {
+		return nil, protocol.Decoded{}, err
+	}
+	decoded, err := protocol.Decode(p, r.Announcement())
+	return p, decoded, err
+}
+
+// Write writes records as JSON Lines.
+func Write(w io.Writer, records []Record) error {
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Read reads JSON Lines records, skipping blank lines.
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLine)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrFormat, line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// ReadLenient reads JSON Lines records like Read, but skips lines that are
+// not valid records, including those longer than Read accepts, instead of
+// failing. It returns the line number of each record and the number of
+// lines skipped.
+func ReadLenient(r io.Reader) (records []Record, lines []int, skipped int, err error) {
+	br := bufio.NewReader(r)
+	for line := 1; ; line++ {
+		b, tooLong, err := readLine(br)
+		if err != nil && err != io.EOF {
+			return records, lines, skipped, err
+		}
+		var record Record
+		switch {
+		case tooLong:
+			skipped++
+		case len(bytes.TrimSpace(b)) == 0:
+		case json.Unmarshal(b, &record) != nil:
+			skipped++
+		default:
+			records, lines = append(records, record), append(lines, line)
+		}
+		if err == io.EOF {
+			return records, lines, skipped, nil
+		}
+	}
+}
+
+// readLine reads a line without its newline. Lines longer than maxLine are
+// consumed and reported as too long.
+func readLine(br *bufio.Reader) (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := br.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > maxLine+1 {
+				line, tooLong = nil, true
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			return bytes.TrimSuffix(line, []byte("\n")), tooLong, err
+		}
+	}
+}
+
+// Append adds records to the file at path, creating it if needed.
+func Append(path string, records ...Record) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, records); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads the announcement file at path.
+func Load(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// LoadLenient reads the announcement file at path with ReadLenient.
+func LoadLenient(path string) (records []Record, lines []int, skipped int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	defer file.Close()
+	return ReadLenient(file)
+}
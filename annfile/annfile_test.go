@@ -0,0 +1,49 @@
+package annfile
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sap-go/protocol"
+	"strings"
+	"testing"
+)
+
+// TestReadLenient checks that Read fails on a malformed line while
+// ReadLenient skips malformed and overlong lines, keeps blank lines out of
+// the count and reports the line number of every record.
+func TestReadLenient(t *testing.T) {
+	p := protocol.DKSAPSecp256k1()
+	var records []Record
+	for i := 0; i < 3; i++ {
+		ann, err := p.RandomAnnouncement()
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, NewRecord(p, ann))
+	}
+	var valid bytes.Buffer
+	if err := Write(&valid, records); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(valid.String(), "\n"), "\n")
+
+	file := strings.Join([]string{
+		lines[0],
+		"not json",
+		"",
+		lines[1],
+		`{"protocol":` + strings.Repeat(" ", maxLine) + `"dksap"}`,
+		lines[2],
+	}, "\n")
+	if _, err := Read(strings.NewReader(file)); !errors.Is(err, ErrFormat) {
+		t.Fatalf("Read: got %v, expected %v", err, ErrFormat)
+	}
+	got, gotLines, skipped, err := ReadLenient(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadLenient: %v", err)
+	}
+	if !reflect.DeepEqual(got, records) || !reflect.DeepEqual(gotLines, []int{1, 4, 6}) || skipped != 2 {
+		t.Fatalf("ReadLenient: got %d records at lines %v with %d skipped, expected 3 at [1 4 6] with 2 skipped", len(got), gotLines, skipped)
+	}
+}
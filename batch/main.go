@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sap-go/bench"
+	"sap-go/harness"
+	"sap-go/protocol"
+	"sap-go/results"
+)
+
+func main() {
+	n := flag.Int("n", 5000, "number of announcements to scan")
+	owned := flag.Int("owned", 1, "number of announcements addressed to the first recipient")
+	runs := flag.Int("runs", 3, "number of runs per number of recipients")
+	maxRecipients := flag.Int("max", 32, "largest number of recipients")
+	protocolName := flag.String("protocol", "", "only run this protocol (default all)")
+	curveName := flag.String("curve", "", "only run this curve (default all)")
+	outDir := flag.String("out", "batch_results", "directory for the CSV file")
+	flag.Parse()
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Println("Error creating output directory:", err)
+		os.Exit(1)
+	}
+
+	// Procs gives the powers of two up to the largest size.
+	sizes := harness.Procs(*maxRecipients)
+	var summaries []results.BatchSummary
+	for _, p := range protocol.All() {
+		if (*protocolName != "" && p.Name() != *protocolName) || (*curveName != "" && p.Curve() != *curveName) {
+			continue
+		}
+		fixture, err := harness.NewFixture(p, &bench.Options{Announcements: *n, Owned: *owned})
+		if err != nil {
+			fmt.Println("Error preparing announcements:", err)
+			os.Exit(1)
+		}
+		protocolSummaries, err := harness.Batch(p, fixture, sizes, *runs)
+		if err != nil {
+			fmt.Printf("Error scanning %s: %v\n", protocol.ID(p), err)
+			os.Exit(1)
+		}
+		summaries = append(summaries, protocolSummaries...)
+	}
+	if len(summaries) == 0 {
+		fmt.Println("No protocol matches -protocol and -curve")
+		os.Exit(2)
+	}
+
+	fmt.Printf("%-20s %-10s %10s %12s %14s %16s %8s\n", "Protocol", "Curve", "Recipients", "Batch (ms)", "Separate (ms)", "Per recipient", "Saving")
+	for _, s := range summaries {
+		fmt.Printf("%-20s %-10s %10d %12.2f %14.2f %16.2f %7.2fx\n", s.Protocol, s.Curve, s.Recipients, s.Batch, s.Separate, s.PerRecipient, s.Saving)
+	}
+
+	fileName := filepath.Join(*outDir, results.BatchFileName(*n))
+	if err := results.SaveBatch(fileName, summaries); err != nil {
+		fmt.Println("Error writing CSV file:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Batch results saved to", fileName)
+}
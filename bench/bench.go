@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"sap-go/config"
+	"time"
+)
+
+// Options controls the shape of the announcement set a search benchmark scans.
+type Options struct {
+	Announcements int
+	Owned         int
+	Seed          int64
+	Runs          int
+	Out           string
+	Dataset       string
+	Profiles      Profiles
+}
+
+// Flags registers the benchmark options on the default flag set.
+func Flags() *Options {
+	o := &Options{}
+	flag.IntVar(&o.Announcements, "n", config.RunNumber, "number of announcements to scan")
+	flag.IntVar(&o.Owned, "owned", 1, "number of announcements addressed to the recipient")
+	flag.Int64Var(&o.Seed, "seed", 0, "seed the recipient, announcements and owned positions are generated from (0 picks a random seed)")
+	flag.IntVar(&o.Runs, "runs", 10, "number of runs per experiment")
+	flag.StringVar(&o.Out, "out", ".", "directory experiment CSV files are written to")
+	flag.StringVar(&o.Dataset, "dataset", "", "scan the announcements in this dataset file instead of generating them")
+	flag.Var(&o.Profiles, "profile", "comma separated profiles to write to -out: cpu, heap, allocs, trace")
+	return o
+}
+
+// OwnedPositions returns a mask of length o.Announcements in which exactly
+// o.Owned randomly chosen entries are set.
+func (o *Options) OwnedPositions() []bool {
+	seed := o.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	owned := o.OwnedCount()
+	positions := make([]bool, o.Announcements)
+	for _, i := range rng.Perm(o.Announcements)[:owned] {
+		positions[i] = true
+	}
+	return positions
+}
+
+// Path returns the location of an output file inside o.Out.
+func (o *Options) Path(fileName string) string {
+	return filepath.Join(o.Out, fileName)
+}
+
+// OwnedCount returns the number of owned announcements, clamped to the size of the set.
+func (o *Options) OwnedCount() int {
+	return min(max(o.Owned, 0), o.Announcements)
+}
+
+// Result describes one full scan over an announcement set.
+type Result struct {
+	Announcements int
+	Owned         int
+	Matches       int
+	ViewTags      bool
+	ViewTagHits   int
+	Duration      time.Duration
+	Allocs        uint64
+}
+
+// FalsePositives returns the number of view tag hits that were not addressed to the recipient.
+func (r Result) FalsePositives() int {
+	if !r.ViewTags {
+		return 0
+	}
+	return r.ViewTagHits - r.Matches
+}
+
+// Throughput returns the number of announcements scanned per second.
+func (r Result) Throughput() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Announcements) / r.Duration.Seconds()
+}
+
+// AllocsPerAnnouncement returns the number of heap allocations per scanned announcement.
+func (r Result) AllocsPerAnnouncement() float64 {
+	if r.Announcements == 0 {
+		return 0
+	}
+	return float64(r.Allocs) / float64(r.Announcements)
+}
+
+// Print reports the scan result on stdout.
+func (r Result) Print(label string) {
+	fmt.Printf("%s: %d/%d matches found in %d announcements", label, r.Matches, r.Owned, r.Announcements)
+	if r.ViewTags {
+		fmt.Printf(", %d view tag hits (%d false positives)", r.ViewTagHits, r.FalsePositives())
+	}
+	fmt.Printf(", %v (%.0f announcements/s, %.1f allocs/announcement)\n", r.Duration, r.Throughput(), r.AllocsPerAnnouncement())
+}
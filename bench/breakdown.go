@@ -0,0 +1,245 @@
+package bench
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Stage is one step of scanning a single announcement.
+type Stage int
+
+const (
+	// StageViewTag computes the recipient's view tag for an announcement.
+	StageViewTag Stage = iota
+	// StageDerive derives the stealth public key.
+	StageDerive
+	// StageHash hashes the stealth public key into address bytes.
+	StageHash
+	// StageFormat formats the address bytes as a 0x prefixed hex address.
+	StageFormat
+	numStages
+)
+
+// Stages lists every stage in scan order.
+var Stages = []Stage{StageViewTag, StageDerive, StageHash, StageFormat}
+
+var stageNames = [numStages]string{"view tag", "derivation", "address hashing", "address formatting"}
+
+func (s Stage) String() string {
+	if s < 0 || s >= numStages {
+		return "stage(" + strconv.Itoa(int(s)) + ")"
+	}
+	return stageNames[s]
+}
+
+// Cost is the time spent in one primitive, such as a pairing, within a stage.
+type Cost struct {
+	Primitive string
+	Calls     int
+	Duration  time.Duration
+}
+
+// Breakdown attributes scan time to the individual stages, and the time of
+// each stage to the primitives the protocol ran in it.
+type Breakdown struct {
+	Protocol      string
+	Curve         string
+	Announcements int
+	Total         time.Duration
+	Durations     [numStages]time.Duration
+	Calls         [numStages]int
+	Primitives    [numStages][]Cost
+}
+
+// Stop charges the time elapsed since start to stage.
+func (b *Breakdown) Stop(stage Stage, start time.Time) {
+	b.Durations[stage] += time.Since(start)
+	b.Calls[stage]++
+}
+
+// AddPrimitive charges calls of primitive that took d in total to stage.
+func (b *Breakdown) AddPrimitive(stage Stage, primitive string, calls int, d time.Duration) {
+	for i := range b.Primitives[stage] {
+		if cost := &b.Primitives[stage][i]; cost.Primitive == primitive {
+			cost.Calls += calls
+			cost.Duration += d
+			return
+		}
+	}
+	b.Primitives[stage] = append(b.Primitives[stage], Cost{Primitive: primitive, Calls: calls, Duration: d})
+}
+
+// Primitive returns the time spent in primitive across all stages.
+func (b *Breakdown) Primitive(primitive string) time.Duration {
+	var d time.Duration
+	for _, costs := range b.Primitives {
+		for _, cost := range costs {
+			if cost.Primitive == primitive {
+				d += cost.Duration
+			}
+		}
+	}
+	return d
+}
+
+// PrimitiveShare returns the percentage of Total spent in primitive.
+func (b *Breakdown) PrimitiveShare(primitive string) float64 {
+	return b.share(b.Primitive(primitive))
+}
+
+// Share returns the percentage of Total spent in stage.
+func (b *Breakdown) Share(stage Stage) float64 {
+	return b.share(b.Durations[stage])
+}
+
+// Untracked returns the part of Total not attributed to any stage (loop and comparison overhead).
+func (b *Breakdown) Untracked() time.Duration {
+	untracked := b.Total
+	for _, d := range b.Durations {
+		untracked -= d
+	}
+	return untracked
+}
+
+// breakdownHeader names the CSV columns. Stage rows have no primitive; the
+// rows of the primitives of a stage follow it.
+var breakdownHeader = []string{"Protocol", "Curve", "Announcements", "Stage", "Primitive", "Calls", "Total (ms)", "Per call (us)", "Share (%)"}
+
+func (b *Breakdown) rows() [][]string {
+	rows := make([][]string, 0, numStages+1)
+	for stage := StageViewTag; stage < numStages; stage++ {
+		rows = append(rows, b.row(stage.String(), "", b.Calls[stage], b.Durations[stage]))
+		for _, cost := range b.Primitives[stage] {
+			rows = append(rows, b.row(stage.String(), cost.Primitive, cost.Calls, cost.Duration))
+		}
+	}
+	other := b.row("other", "", 0, b.Untracked())
+	other[5], other[7] = "", ""
+	return append(rows, other)
+}
+
+func (b *Breakdown) row(stage, primitive string, calls int, d time.Duration) []string {
+	perCall := 0.0
+	if calls > 0 {
+		perCall = float64(d.Microseconds()) / float64(calls)
+	}
+	return []string{
+		b.Protocol, b.Curve, strconv.Itoa(b.Announcements), stage, primitive, strconv.Itoa(calls),
+		fmt.Sprintf("%.2f", milliseconds(d)), fmt.Sprintf("%.2f", perCall), fmt.Sprintf("%.1f", b.share(d)),
+	}
+}
+
+func (b *Breakdown) share(d time.Duration) float64 {
+	if b.Total <= 0 {
+		return 0
+	}
+	return float64(d) / float64(b.Total) * 100
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// Print writes the breakdown as an aligned table on stdout.
+func (b *Breakdown) Print() {
+	fmt.Printf("%-20s %-10s %-24s %8s %12s %14s %9s\n", "Protocol", "Curve", "Stage", "Calls", "Total (ms)", "Per call (us)", "Share")
+	for _, row := range b.rows() {
+		name := row[3]
+		if row[4] != "" {
+			name = "  " + row[4]
+		}
+		fmt.Printf("%-20s %-10s %-24s %8s %12s %14s %8s%%\n", row[0], row[1], name, row[5], row[6], row[7], row[8])
+	}
+	fmt.Printf("%-20s %-10s %-24s %8d %12.2f\n", b.Protocol, b.Curve, "total", b.Announcements, milliseconds(b.Total))
+}
+
+// BreakdownFileName returns the canonical CSV file name for a stage breakdown.
+func BreakdownFileName(protocol, curve string, announcements int) string {
+	return fmt.Sprintf("stage_breakdown_%s_%s_%d_public_keys.csv", protocol, curve, announcements)
+}
+
+// WriteBreakdowns writes breakdowns as CSV, one row per protocol, curve,
+// stage and primitive.
+func WriteBreakdowns(w io.Writer, breakdowns []Breakdown) error {
+	rows := [][]string{breakdownHeader}
+	for i := range breakdowns {
+		rows = append(rows, breakdowns[i].rows()...)
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// SaveBreakdowns writes breakdowns to the CSV file at path.
+func SaveBreakdowns(path string, breakdowns []Breakdown) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBreakdowns(file, breakdowns); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// LoadBreakdowns reads breakdowns previously written by SaveBreakdowns.
+func LoadBreakdowns(path string) ([]Breakdown, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	var breakdowns []Breakdown
+	index := make(map[string]int)
+	for line, row := range rows[min(len(rows), 1):] {
+		if len(row) != len(breakdownHeader) {
+			return nil, fmt.Errorf("%s: line %d: expected %d columns", path, line+2, len(breakdownHeader))
+		}
+		announcements, err := strconv.Atoi(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: invalid announcement count: %w", path, line+2, err)
+		}
+		totalMs, err := strconv.ParseFloat(row[6], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: line %d: invalid duration: %w", path, line+2, err)
+		}
+		duration := time.Duration(totalMs * float64(time.Millisecond))
+
+		key := row[0] + "/" + row[1] + "/" + row[2]
+		i, ok := index[key]
+		if !ok {
+			i = len(breakdowns)
+			index[key] = i
+			breakdowns = append(breakdowns, Breakdown{Protocol: row[0], Curve: row[1], Announcements: announcements})
+		}
+		b := &breakdowns[i]
+		if row[4] == "" {
+			b.Total += duration
+		}
+
+		for stage := StageViewTag; stage < numStages; stage++ {
+			if row[3] != stage.String() {
+				continue
+			}
+			calls, err := strconv.Atoi(row[5])
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: invalid call count: %w", path, line+2, err)
+			}
+			if row[4] != "" {
+				b.AddPrimitive(stage, row[4], calls, duration)
+			} else {
+				b.Durations[stage], b.Calls[stage] = duration, calls
+			}
+		}
+	}
+	return breakdowns, nil
+}
@@ -0,0 +1,159 @@
+package bench
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+)
+
+// Profile is a kind of profile that can be recorded around a measurement.
+type Profile string
+
+const (
+	// ProfileCPU samples CPU usage.
+	ProfileCPU Profile = "cpu"
+	// ProfileHeap records live heap objects at the end of the measurement.
+	ProfileHeap Profile = "heap"
+	// ProfileAllocs records all allocations made since the program started.
+	ProfileAllocs Profile = "allocs"
+	// ProfileTrace records an execution trace.
+	ProfileTrace Profile = "trace"
+)
+
+// Profiles is the set of profiles to record. It implements flag.Value.
+type Profiles []Profile
+
+func (p *Profiles) String() string {
+	names := make([]string, len(*p))
+	for i, profile := range *p {
+		names[i] = string(profile)
+	}
+	return strings.Join(names, ",")
+}
+
+func (p *Profiles) Set(value string) error {
+	*p = nil
+	for _, name := range strings.Split(value, ",") {
+		switch profile := Profile(strings.TrimSpace(name)); profile {
+		case ProfileCPU, ProfileHeap, ProfileAllocs, ProfileTrace:
+			*p = append(*p, profile)
+		case "":
+		default:
+			return fmt.Errorf("unknown profile %q", name)
+		}
+	}
+	return nil
+}
+
+func (p Profiles) has(profile Profile) bool {
+	for _, q := range p {
+		if q == profile {
+			return true
+		}
+	}
+	return false
+}
+
+// ProfileFileName returns the file name of a profile for one configuration.
+func ProfileFileName(profile Profile, protocol, curve string, announcements int, label string) string {
+	ext := "pprof"
+	if profile == ProfileTrace {
+		ext = "trace"
+	}
+	return fmt.Sprintf("%s_%s_%s_%d_%s.%s", profile, protocol, curve, announcements, label, ext)
+}
+
+// Profiler records the profiles selected in Options around one measurement.
+type Profiler struct {
+	opts          *Options
+	protocol      string
+	curve         string
+	announcements int
+	label         string
+	cpu           *os.File
+	trace         *os.File
+	files         []string
+}
+
+// StartProfiling starts the CPU profile and execution trace, if selected,
+// for the configuration. label distinguishes measurements of the same
+// configuration, e.g. "experiment" and "breakdown".
+func StartProfiling(opts *Options, protocol, curve string, announcements int, label string) (*Profiler, error) {
+	p := &Profiler{opts: opts, protocol: protocol, curve: curve, announcements: announcements, label: label}
+	if opts.Profiles.has(ProfileCPU) {
+		file, err := p.create(ProfileCPU)
+		if err != nil {
+			return nil, err
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("error starting CPU profile: %w", err)
+		}
+		p.cpu = file
+	}
+	if opts.Profiles.has(ProfileTrace) {
+		file, err := p.create(ProfileTrace)
+		if err != nil {
+			p.Stop()
+			return nil, err
+		}
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			p.Stop()
+			return nil, fmt.Errorf("error starting trace: %w", err)
+		}
+		p.trace = file
+	}
+	return p, nil
+}
+
+func (p *Profiler) create(profile Profile) (*os.File, error) {
+	path := p.opts.Path(ProfileFileName(profile, p.protocol, p.curve, p.announcements, p.label))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	p.files = append(p.files, path)
+	return file, nil
+}
+
+// Stop stops the running profiles, writes the heap and allocation profiles
+// and returns the paths of all profile files written.
+func (p *Profiler) Stop() ([]string, error) {
+	var firstErr error
+	keep := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		keep(p.cpu.Close())
+		p.cpu = nil
+	}
+	if p.trace != nil {
+		trace.Stop()
+		keep(p.trace.Close())
+		p.trace = nil
+	}
+	for _, profile := range []Profile{ProfileHeap, ProfileAllocs} {
+		if !p.opts.Profiles.has(profile) {
+			continue
+		}
+		if profile == ProfileHeap {
+			// report live objects as of the end of the measurement
+			runtime.GC()
+		}
+		file, err := p.create(profile)
+		if err != nil {
+			keep(err)
+			continue
+		}
+		keep(pprof.Lookup(string(profile)).WriteTo(file, 0))
+		keep(file.Close())
+	}
+	return p.files, firstErr
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sap-go/results"
+)
+
+// comparison is the outcome of comparing one configuration across two result sets.
+type comparison struct {
+	key       results.Key
+	baseline  results.Summary
+	candidate results.Summary
+	// deltaPct is the change of the mean in percent of the baseline mean.
+	// It is undefined, and hasDelta false, when the baseline mean is zero.
+	deltaPct float64
+	hasDelta bool
+	pValue   float64
+}
+
+func (c comparison) significant(alpha float64) bool {
+	return c.pValue < alpha
+}
+
+// regressed reports a significant slowdown beyond thresholdPct. Without a
+// delta, any significant slowdown from a zero baseline counts.
+func (c comparison) regressed(thresholdPct, alpha float64) bool {
+	if !c.hasDelta {
+		return c.significant(alpha) && c.candidate.Mean > c.baseline.Mean
+	}
+	return c.significant(alpha) && c.deltaPct > thresholdPct
+}
+
+// delta formats deltaPct, or n/a without a delta.
+func (c comparison) delta() string {
+	if !c.hasDelta {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.2f%%", c.deltaPct)
+}
+
+// compare pairs up configurations present in both result sets.
+func compare(baseline, candidate []results.Record) (comparisons []comparison, missing []results.Key) {
+	candidateSummaries := make(map[results.Key]results.Summary)
+	for _, s := range results.Summarize(candidate) {
+		candidateSummaries[s.Key] = s
+	}
+
+	for _, base := range results.Summarize(baseline) {
+		cand, ok := candidateSummaries[base.Key]
+		if !ok {
+			missing = append(missing, base.Key)
+			continue
+		}
+		_, _, p := results.WelchTTest(base.Durations, cand.Durations)
+		c := comparison{key: base.Key, baseline: base, candidate: cand, pValue: p}
+		if base.Mean != 0 {
+			c.deltaPct, c.hasDelta = (cand.Mean-base.Mean)/base.Mean*100, true
+		}
+		comparisons = append(comparisons, c)
+	}
+	return comparisons, missing
+}
+
+func main() {
+	baselinePath := flag.String("baseline", "", "baseline result CSV file or directory")
+	candidatePath := flag.String("candidate", "", "candidate result CSV file or directory")
+	threshold := flag.Float64("threshold", 5, "maximum tolerated slowdown in percent")
+	alpha := flag.Float64("alpha", 0.05, "significance level of Welch's t-test")
+	protocol := flag.String("protocol", "ecpdksap", "protocol assumed for result files without a Protocol column")
+	curve := flag.String("curve", "bn254", "curve assumed for result files without a Curve column")
+	flag.Parse()
+
+	if *baselinePath == "" || *candidatePath == "" {
+		fmt.Println("Both -baseline and -candidate are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	baseline, err := results.LoadPath(*baselinePath, *protocol, *curve)
+	if err != nil {
+		fmt.Println("Error loading baseline results:", err)
+		os.Exit(2)
+	}
+	candidate, err := results.LoadPath(*candidatePath, *protocol, *curve)
+	if err != nil {
+		fmt.Println("Error loading candidate results:", err)
+		os.Exit(2)
+	}
+
+	comparisons, missing := compare(baseline, candidate)
+	if len(comparisons) == 0 {
+		fmt.Println("No configuration is present in both result sets")
+		os.Exit(2)
+	}
+
+	if regressions := report(os.Stdout, comparisons, missing, *threshold, *alpha); regressions > 0 {
+		fmt.Printf("%d configuration(s) regressed by more than %.2f%% (alpha %.3f)\n", regressions, *threshold, *alpha)
+		os.Exit(1)
+	}
+	fmt.Println("No significant regressions")
+}
+
+// report prints a verdict per comparison and the configurations missing from
+// the candidate, and returns the number of regressions.
+func report(w io.Writer, comparisons []comparison, missing []results.Key, threshold, alpha float64) int {
+	regressions := 0
+	fmt.Fprintf(w, "%-28s %8s %12s %12s %9s %8s  %s\n", "Configuration", "Keys", "Baseline ms", "Candidate ms", "Delta", "p", "Verdict")
+	for _, c := range comparisons {
+		verdict := "~"
+		switch {
+		case c.regressed(threshold, alpha):
+			verdict = "REGRESSION"
+			regressions++
+		case c.significant(alpha) && c.candidate.Mean < c.baseline.Mean:
+			verdict = "faster"
+		case c.significant(alpha):
+			verdict = "slower"
+		}
+		fmt.Fprintf(w, "%-28s %8d %12.2f %12.2f %9s %8.4f  %s\n",
+			c.key.Series(), c.key.PublicKeys, c.baseline.Mean, c.candidate.Mean, c.delta(), c.pValue, verdict)
+	}
+	for _, key := range missing {
+		fmt.Fprintf(w, "%-28s %8d missing from candidate\n", key.Series(), key.PublicKeys)
+	}
+	return regressions
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"sap-go/results"
+	"strings"
+	"testing"
+)
+
+// records returns one record per duration of the configuration.
+func records(protocol string, publicKeys int, durations ...float64) []results.Record {
+	var out []results.Record
+	for i, d := range durations {
+		out = append(out, results.Record{Protocol: protocol, Curve: "bn254", Run: i + 1, DurationMs: d, PublicKeys: publicKeys})
+	}
+	return out
+}
+
+// TestReport compares a baseline with a candidate that is 10% slower in one
+// configuration, 2% slower in another, unchanged in a third and slower from
+// a zero baseline in a fourth, and is missing a fifth. With a 5% threshold,
+// only the 10% slowdown and the slowdown from zero are regressions.
+func TestReport(t *testing.T) {
+	base := []float64{100, 101, 99, 100, 102, 98}
+	var baseline, candidate []results.Record
+	baseline = append(baseline, records("a", 5000, base...)...)
+	candidate = append(candidate, records("a", 5000, 110, 111, 109, 110, 112, 108)...)
+	baseline = append(baseline, records("b", 5000, base...)...)
+	candidate = append(candidate, records("b", 5000, 102, 103, 101, 102, 104, 100)...)
+	baseline = append(baseline, records("c", 5000, base...)...)
+	candidate = append(candidate, records("c", 5000, base...)...)
+	baseline = append(baseline, records("d", 5000, 0, 0, 0)...)
+	candidate = append(candidate, records("d", 5000, 1, 1, 1)...)
+	baseline = append(baseline, records("e", 5000, base...)...)
+
+	comparisons, missing := compare(baseline, candidate)
+	if len(comparisons) != 4 || len(missing) != 1 || missing[0].Protocol != "e" {
+		t.Fatalf("compare: got %d comparisons and missing %v", len(comparisons), missing)
+	}
+	verdicts := map[string]string{"a": "REGRESSION", "b": "slower", "c": "~", "d": "REGRESSION"}
+	for _, c := range comparisons {
+		if got := c.regressed(5, 0.05); got != (verdicts[c.key.Protocol] == "REGRESSION") {
+			t.Errorf("%s: regressed %v, expected verdict %s (delta %s, p %v)", c.key.Protocol, got, verdicts[c.key.Protocol], c.delta(), c.pValue)
+		}
+	}
+
+	var out bytes.Buffer
+	if n := report(&out, comparisons, missing, 5, 0.05); n != 2 {
+		t.Fatalf("report: got %d regressions, expected 2:\n%s", n, out.String())
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n")[1:] {
+		fields := strings.Fields(line)
+		protocol := strings.TrimSuffix(fields[0], "/bn254")
+		if protocol == "e" {
+			if !strings.HasSuffix(line, "missing from candidate") {
+				t.Errorf("missing configuration reported as %q", line)
+			}
+			continue
+		}
+		if fields[len(fields)-1] != verdicts[protocol] {
+			t.Errorf("%s: reported %q, expected verdict %s", protocol, line, verdicts[protocol])
+		}
+		if protocol == "d" && fields[4] != "n/a" {
+			t.Errorf("zero baseline: delta %s, expected n/a", fields[4])
+		}
+	}
+	if n := report(&out, comparisons, missing, 15, 0.05); n != 1 {
+		t.Fatalf("report with a 15%% threshold: got %d regressions, expected only the zero baseline", n)
+	}
+}
@@ -0,0 +1,220 @@
+package chart
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	width        = 960
+	height       = 540
+	marginLeft   = 90
+	marginRight  = 220
+	marginTop    = 50
+	marginBottom = 70
+	plotWidth    = width - marginLeft - marginRight
+	plotHeight   = height - marginTop - marginBottom
+	fontFamily   = "STIXGeneral, Times New Roman, serif"
+)
+
+// palette is the default matplotlib colour cycle.
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+func color(i int) string {
+	return palette[i%len(palette)]
+}
+
+// Series is a named sequence of values, one per category or point.
+type Series struct {
+	Name   string
+	Values []float64
+}
+
+// BarChart is a grouped bar chart: one group per category, one bar per series.
+type BarChart struct {
+	Title      string
+	XLabel     string
+	YLabel     string
+	Categories []string
+	Series     []Series
+}
+
+// LineChart plots every series against shared X values.
+type LineChart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	X      []float64
+	Series []Series
+}
+
+// svg accumulates SVG elements and remembers the first write error.
+type svg struct {
+	w   io.Writer
+	err error
+}
+
+func (s *svg) printf(format string, args ...interface{}) {
+	if s.err != nil {
+		return
+	}
+	_, s.err = fmt.Fprintf(s.w, format, args...)
+}
+
+func (s *svg) text(x, y float64, anchor string, size int, value string, extra string) {
+	s.printf(`<text x="%.1f" y="%.1f" text-anchor="%s" font-size="%d"%s>%s</text>`+"\n", x, y, anchor, size, extra, html.EscapeString(value))
+}
+
+func (s *svg) begin(title, xLabel, yLabel string) {
+	s.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s">`+"\n", width, height, width, height, fontFamily)
+	s.printf(`<rect width="100%%" height="100%%" fill="white"/>` + "\n")
+	s.text(marginLeft+plotWidth/2, marginTop/2+6, "middle", 20, title, "")
+	s.text(marginLeft+plotWidth/2, height-20, "middle", 16, xLabel, "")
+	s.text(22, marginTop+plotHeight/2, "middle", 16, yLabel, fmt.Sprintf(` transform="rotate(-90 22 %d)"`, marginTop+plotHeight/2))
+}
+
+func (s *svg) end() {
+	s.printf("</svg>\n")
+}
+
+// yAxis draws horizontal grid lines and tick labels and returns the function
+// mapping a value to its vertical pixel position.
+func (s *svg) yAxis(maxValue float64) func(float64) float64 {
+	ticks := niceTicks(maxValue)
+	top := ticks[len(ticks)-1]
+	scale := func(v float64) float64 {
+		return marginTop + plotHeight - v/top*plotHeight
+	}
+	for _, t := range ticks {
+		y := scale(t)
+		s.printf(`<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#dddddd"/>`+"\n", marginLeft, y, marginLeft+plotWidth, y)
+		s.text(marginLeft-8, y+4, "end", 12, formatValue(t), "")
+	}
+	s.printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", marginLeft, marginTop, marginLeft, marginTop+plotHeight)
+	s.printf(`<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", marginLeft, marginTop+plotHeight, marginLeft+plotWidth, marginTop+plotHeight)
+	return scale
+}
+
+func (s *svg) legend(series []Series) {
+	x := float64(marginLeft + plotWidth + 20)
+	for i, serie := range series {
+		y := float64(marginTop + 10 + i*22)
+		s.printf(`<rect x="%.1f" y="%.1f" width="14" height="14" fill="%s"/>`+"\n", x, y, color(i))
+		s.text(x+20, y+12, "start", 13, serie.Name, "")
+	}
+}
+
+// Render writes the bar chart as an SVG document.
+func (c *BarChart) Render(w io.Writer) error {
+	s := &svg{w: w}
+	s.begin(c.Title, c.XLabel, c.YLabel)
+	scale := s.yAxis(maxOf(c.Series))
+
+	groupWidth := float64(plotWidth) / float64(max(len(c.Categories), 1))
+	barWidth := groupWidth * 0.8 / float64(max(len(c.Series), 1))
+	for i, category := range c.Categories {
+		groupStart := marginLeft + float64(i)*groupWidth + groupWidth*0.1
+		for j, serie := range c.Series {
+			if i >= len(serie.Values) || math.IsNaN(serie.Values[i]) {
+				continue
+			}
+			value := serie.Values[i]
+			x := groupStart + float64(j)*barWidth
+			y := scale(value)
+			s.printf(`<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s: %s</title></rect>`+"\n",
+				x, y, barWidth, marginTop+plotHeight-y, color(j), html.EscapeString(serie.Name), formatValue(value))
+			s.text(x+barWidth/2, y-3, "middle", 9, formatValue(value), "")
+		}
+		s.text(groupStart+groupWidth*0.4, marginTop+plotHeight+18, "middle", 13, category, "")
+	}
+
+	s.legend(c.Series)
+	s.end()
+	return s.err
+}
+
+// Render writes the line chart as an SVG document. NaN values are skipped.
+func (c *LineChart) Render(w io.Writer) error {
+	s := &svg{w: w}
+	s.begin(c.Title, c.XLabel, c.YLabel)
+	scale := s.yAxis(maxOf(c.Series))
+
+	var maxX float64
+	for _, x := range c.X {
+		maxX = math.Max(maxX, x)
+	}
+	xTicks := niceTicks(maxX)
+	right := xTicks[len(xTicks)-1]
+	scaleX := func(v float64) float64 {
+		return marginLeft + v/right*plotWidth
+	}
+	for _, t := range xTicks {
+		s.text(scaleX(t), marginTop+plotHeight+18, "middle", 12, formatValue(t), "")
+	}
+
+	for i, serie := range c.Series {
+		var points []string
+		for j, value := range serie.Values {
+			if j >= len(c.X) || math.IsNaN(value) {
+				continue
+			}
+			x, y := scaleX(c.X[j]), scale(value)
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+			s.printf(`<circle cx="%.1f" cy="%.1f" r="3.5" fill="%s"><title>%s: %s</title></circle>`+"\n", x, y, color(i), html.EscapeString(serie.Name), formatValue(value))
+		}
+		s.printf(`<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), color(i))
+	}
+
+	s.legend(c.Series)
+	s.end()
+	return s.err
+}
+
+func maxOf(series []Series) float64 {
+	var m float64
+	for _, serie := range series {
+		for _, v := range serie.Values {
+			if !math.IsNaN(v) {
+				m = math.Max(m, v)
+			}
+		}
+	}
+	return m
+}
+
+// niceTicks returns evenly spaced round tick values from 0 covering maxValue.
+func niceTicks(maxValue float64) []float64 {
+	if maxValue <= 0 {
+		return []float64{0, 1}
+	}
+	rough := maxValue / 5
+	magnitude := math.Pow(10, math.Floor(math.Log10(rough)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if m*magnitude >= rough {
+			step = m * magnitude
+			break
+		}
+	}
+	ticks := []float64{0}
+	for t := step; ticks[len(ticks)-1] < maxValue; t += step {
+		ticks = append(ticks, t)
+	}
+	return ticks
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	if math.Abs(v) >= 10 {
+		return fmt.Sprintf("%.1f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
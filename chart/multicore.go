@@ -0,0 +1,73 @@
+package chart
+
+import (
+	"math"
+	"path/filepath"
+	"sap-go/results"
+	"sort"
+)
+
+// File names of the charts written by WriteMulticoreCharts.
+const (
+	SpeedupChartFile    = "multicore_speedup.svg"
+	EfficiencyChartFile = "multicore_efficiency.svg"
+)
+
+// WriteMulticoreCharts writes the speedup and parallel efficiency charts of a
+// multi-core scaling experiment into outDir and returns the paths of the files.
+func WriteMulticoreCharts(summaries []results.CoreSummary, outDir string) ([]string, error) {
+	procsSeen := make(map[int]bool)
+	var procs []int
+	bySeries := make(map[string]map[int]results.CoreSummary)
+	var seriesNames []string
+	for _, s := range summaries {
+		if !procsSeen[s.Procs] {
+			procsSeen[s.Procs] = true
+			procs = append(procs, s.Procs)
+		}
+		if bySeries[s.Series()] == nil {
+			bySeries[s.Series()] = make(map[int]results.CoreSummary)
+			seriesNames = append(seriesNames, s.Series())
+		}
+		bySeries[s.Series()][s.Procs] = s
+	}
+	sort.Ints(procs)
+	sort.Strings(seriesNames)
+
+	x := make([]float64, len(procs))
+	ideal := Series{Name: "ideal", Values: make([]float64, len(procs))}
+	for i, p := range procs {
+		x[i] = float64(p)
+		ideal.Values[i] = float64(p)
+	}
+	speedup := []Series{ideal}
+	var efficiency []Series
+	for _, name := range seriesNames {
+		s := Series{Name: name, Values: make([]float64, len(procs))}
+		e := Series{Name: name, Values: make([]float64, len(procs))}
+		for i, p := range procs {
+			summary, ok := bySeries[name][p]
+			if !ok {
+				s.Values[i], e.Values[i] = math.NaN(), math.NaN()
+				continue
+			}
+			s.Values[i] = summary.Speedup
+			e.Values[i] = summary.Efficiency * 100
+		}
+		speedup = append(speedup, s)
+		efficiency = append(efficiency, e)
+	}
+
+	speedupChart := &LineChart{Title: "Multi-core Speedup", XLabel: "GOMAXPROCS", YLabel: "Speedup", X: x, Series: speedup}
+	efficiencyChart := &LineChart{Title: "Parallel Efficiency", XLabel: "GOMAXPROCS", YLabel: "Efficiency (%)", X: x, Series: efficiency}
+
+	speedupPath := filepath.Join(outDir, SpeedupChartFile)
+	efficiencyPath := filepath.Join(outDir, EfficiencyChartFile)
+	if err := writeChart(speedupPath, speedupChart.Render); err != nil {
+		return nil, err
+	}
+	if err := writeChart(efficiencyPath, efficiencyChart.Render); err != nil {
+		return nil, err
+	}
+	return []string{speedupPath, efficiencyPath}, nil
+}
@@ -0,0 +1,88 @@
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sap-go/results"
+	"strconv"
+)
+
+// File names of the charts written by WriteResultCharts.
+const (
+	BarChartFile  = "performance_comparison.svg"
+	LineChartFile = "scaling.svg"
+)
+
+// WriteResultCharts writes the grouped bar chart and the scaling line chart
+// for the given summaries into outDir and returns the paths of the files.
+func WriteResultCharts(summaries []results.Summary, outDir string) ([]string, error) {
+	seriesNames, publicKeys := results.Series(summaries)
+
+	means := make(map[string]map[int]float64, len(seriesNames))
+	for _, s := range summaries {
+		if means[s.Series()] == nil {
+			means[s.Series()] = make(map[int]float64)
+		}
+		means[s.Series()][s.PublicKeys] = s.Mean
+	}
+
+	categories := make([]string, len(publicKeys))
+	x := make([]float64, len(publicKeys))
+	for i, n := range publicKeys {
+		categories[i] = strconv.Itoa(n)
+		x[i] = float64(n)
+	}
+
+	series := make([]Series, 0, len(seriesNames))
+	for _, name := range seriesNames {
+		values := make([]float64, len(publicKeys))
+		for i, n := range publicKeys {
+			mean, ok := means[name][n]
+			if !ok {
+				mean = math.NaN()
+			}
+			values[i] = mean
+		}
+		series = append(series, Series{Name: name, Values: values})
+	}
+
+	bar := &BarChart{
+		Title:      "Performance Comparison",
+		XLabel:     "Number of Announcements",
+		YLabel:     "Milliseconds (ms)",
+		Categories: categories,
+		Series:     series,
+	}
+	line := &LineChart{
+		Title:  "Scan Time Scaling",
+		XLabel: "Number of Announcements",
+		YLabel: "Milliseconds (ms)",
+		X:      x,
+		Series: series,
+	}
+
+	barPath := filepath.Join(outDir, BarChartFile)
+	linePath := filepath.Join(outDir, LineChartFile)
+	if err := writeChart(barPath, bar.Render); err != nil {
+		return nil, err
+	}
+	if err := writeChart(linePath, line.Render); err != nil {
+		return nil, err
+	}
+	return []string{barPath, linePath}, nil
+}
+
+func writeChart(path string, render func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(file); err != nil {
+		file.Close()
+		return fmt.Errorf("error rendering %s: %w", path, err)
+	}
+	return file.Close()
+}
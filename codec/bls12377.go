@@ -0,0 +1,236 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+// Flag bits in the most significant byte of gnark's BLS12-377 point encodings.
+const (
+	bls12377Mask               byte = 0b111 << 5
+	bls12377CompressedSmall    byte = 0b100 << 5
+	bls12377CompressedLarge    byte = 0b101 << 5
+	bls12377CompressedInfinity byte = 0b110 << 5
+)
+
+// The curve constants b of G1 and of the twist, recovered from the generators.
+var bls12377G1B, bls12377G2B = func() (fp.Element, bls12377.E2) {
+	_, _, g1, g2 := bls12377.Generators()
+	var b1, x3 fp.Element
+	b1.Square(&g1.Y)
+	x3.Square(&g1.X).Mul(&x3, &g1.X)
+	b1.Sub(&b1, &x3)
+
+	var b2, x3Twist bls12377.E2
+	b2.Square(&g2.Y)
+	x3Twist.Square(&g2.X).Mul(&x3Twist, &g2.X)
+	b2.Sub(&b2, &x3Twist)
+	return b1, b2
+}()
+
+// MarshalBLS12377Scalar returns the tagged encoding of a BLS12-377 scalar.
+func MarshalBLS12377Scalar(s *fr.Element) []byte {
+	b := s.Bytes()
+	return Marshal(CurveBLS12377, KindScalar, b[:])
+}
+
+// UnmarshalBLS12377Scalar parses a tagged BLS12-377 scalar.
+func UnmarshalBLS12377Scalar(b []byte) (fr.Element, error) {
+	_, p, err := payload(b, CurveBLS12377, KindScalar)
+	if err != nil {
+		return fr.Element{}, err
+	}
+	return DecodeBLS12377Scalar(p)
+}
+
+// DecodeBLS12377Scalar parses a big-endian scalar, rejecting values not reduced
+// modulo the group order and zero.
+func DecodeBLS12377Scalar(b []byte) (fr.Element, error) {
+	var s fr.Element
+	if err := checkLength(b, "scalar", fr.Bytes); err != nil {
+		return s, err
+	}
+	if err := s.SetBytesCanonical(b); err != nil {
+		return s, fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	if s.IsZero() {
+		return s, ErrZero
+	}
+	return s, nil
+}
+
+// MarshalBLS12377G1 returns the tagged encoding of a G1 point.
+func MarshalBLS12377G1(p *bls12377.G1Affine, compressed bool) []byte {
+	if compressed {
+		b := p.Bytes()
+		return Marshal(CurveBLS12377, KindG1Compressed, b[:])
+	}
+	b := p.RawBytes()
+	return Marshal(CurveBLS12377, KindG1Uncompressed, b[:])
+}
+
+// UnmarshalBLS12377G1 parses a tagged G1 point in either form.
+func UnmarshalBLS12377G1(b []byte) (bls12377.G1Affine, error) {
+	kind, p, err := payload(b, CurveBLS12377, KindG1Compressed, KindG1Uncompressed)
+	if err != nil {
+		return bls12377.G1Affine{}, err
+	}
+	if kind == KindG1Compressed {
+		if err := checkLength(p, "compressed G1 point", bls12377.SizeOfG1AffineCompressed); err != nil {
+			return bls12377.G1Affine{}, err
+		}
+	} else if err := checkLength(p, "uncompressed G1 point", bls12377.SizeOfG1AffineUncompressed); err != nil {
+		return bls12377.G1Affine{}, err
+	}
+	return DecodeBLS12377G1(p)
+}
+
+// DecodeBLS12377G1 parses an untagged G1 point, compressed or uncompressed
+// depending on its length, and rejects non-canonical encodings, points off
+// the curve or outside the subgroup and the point at infinity.
+func DecodeBLS12377G1(b []byte) (bls12377.G1Affine, error) {
+	var p bls12377.G1Affine
+	if err := checkLength(b, "G1 point", bls12377.SizeOfG1AffineCompressed, bls12377.SizeOfG1AffineUncompressed); err != nil {
+		return p, err
+	}
+	if len(b) == bls12377.SizeOfG1AffineUncompressed {
+		if p.X.SetBytesCanonical(b[:fp.Bytes]) != nil || p.Y.SetBytesCanonical(b[fp.Bytes:]) != nil {
+			return p, ErrNonCanonical
+		}
+		if err := Validate(&p); err != nil {
+			return p, err
+		}
+		return p, nil
+	}
+
+	if err := bls12377CompressedFlags(b[0]); err != nil {
+		return p, err
+	}
+	x := bytes.Clone(b)
+	x[0] &^= bls12377Mask
+	if p.X.SetBytesCanonical(x) != nil {
+		return p, ErrNonCanonical
+	}
+	var y2 fp.Element
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bls12377G1B)
+	if y2.Legendre() == -1 {
+		return p, ErrNotOnCurve
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, ErrNotInSubgroup
+	}
+	// SetBytes has checked the subgroup and the flags excluded infinity.
+	return p, nil
+}
+
+// MarshalBLS12377G2 returns the tagged encoding of a G2 point.
+func MarshalBLS12377G2(p *bls12377.G2Affine, compressed bool) []byte {
+	if compressed {
+		b := p.Bytes()
+		return Marshal(CurveBLS12377, KindG2Compressed, b[:])
+	}
+	b := p.RawBytes()
+	return Marshal(CurveBLS12377, KindG2Uncompressed, b[:])
+}
+
+// UnmarshalBLS12377G2 parses a tagged G2 point in either form.
+func UnmarshalBLS12377G2(b []byte) (bls12377.G2Affine, error) {
+	kind, p, err := payload(b, CurveBLS12377, KindG2Compressed, KindG2Uncompressed)
+	if err != nil {
+		return bls12377.G2Affine{}, err
+	}
+	if kind == KindG2Compressed {
+		if err := checkLength(p, "compressed G2 point", bls12377.SizeOfG2AffineCompressed); err != nil {
+			return bls12377.G2Affine{}, err
+		}
+	} else if err := checkLength(p, "uncompressed G2 point", bls12377.SizeOfG2AffineUncompressed); err != nil {
+		return bls12377.G2Affine{}, err
+	}
+	return DecodeBLS12377G2(p)
+}
+
+// DecodeBLS12377G2 parses an untagged G2 point like DecodeBLS12377G1.
+func DecodeBLS12377G2(b []byte) (bls12377.G2Affine, error) {
+	var p bls12377.G2Affine
+	if err := checkLength(b, "G2 point", bls12377.SizeOfG2AffineCompressed, bls12377.SizeOfG2AffineUncompressed); err != nil {
+		return p, err
+	}
+	if len(b) == bls12377.SizeOfG2AffineUncompressed {
+		// X.A1 | X.A0 | Y.A1 | Y.A0
+		if p.X.A1.SetBytesCanonical(b[:fp.Bytes]) != nil || p.X.A0.SetBytesCanonical(b[fp.Bytes:2*fp.Bytes]) != nil ||
+			p.Y.A1.SetBytesCanonical(b[2*fp.Bytes:3*fp.Bytes]) != nil || p.Y.A0.SetBytesCanonical(b[3*fp.Bytes:]) != nil {
+			return p, ErrNonCanonical
+		}
+		if err := Validate(&p); err != nil {
+			return p, err
+		}
+		return p, nil
+	}
+
+	if err := bls12377CompressedFlags(b[0]); err != nil {
+		return p, err
+	}
+	x := bytes.Clone(b)
+	x[0] &^= bls12377Mask
+	if p.X.A1.SetBytesCanonical(x[:fp.Bytes]) != nil || p.X.A0.SetBytesCanonical(x[fp.Bytes:]) != nil {
+		return p, ErrNonCanonical
+	}
+	var y2 bls12377.E2
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bls12377G2B)
+	if y2.Legendre() == -1 {
+		return p, ErrNotOnCurve
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, ErrNotInSubgroup
+	}
+	return p, nil
+}
+
+func bls12377CompressedFlags(msb byte) error {
+	switch msb & bls12377Mask {
+	case bls12377CompressedSmall, bls12377CompressedLarge:
+		return nil
+	case bls12377CompressedInfinity:
+		return ErrIdentity
+	}
+	return ErrNonCanonical
+}
+
+// MarshalBLS12377GT returns the tagged encoding of a GT element.
+func MarshalBLS12377GT(z *bls12377.GT) []byte {
+	b := z.Bytes()
+	return Marshal(CurveBLS12377, KindGT, b[:])
+}
+
+// UnmarshalBLS12377GT parses a tagged GT element.
+func UnmarshalBLS12377GT(b []byte) (bls12377.GT, error) {
+	_, p, err := payload(b, CurveBLS12377, KindGT)
+	if err != nil {
+		return bls12377.GT{}, err
+	}
+	return DecodeBLS12377GT(p)
+}
+
+// DecodeBLS12377GT parses an untagged GT element, rejecting non-canonical
+// coordinates, elements of order other than r and the identity.
+func DecodeBLS12377GT(b []byte) (bls12377.GT, error) {
+	var z bls12377.GT
+	if err := checkLength(b, "GT element", bls12377.SizeOfGT); err != nil {
+		return z, err
+	}
+	if err := z.SetBytes(b); err != nil {
+		return z, fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	if z.IsOne() {
+		return z, ErrIdentity
+	}
+	var zr bls12377.GT
+	if zr.Exp(z, fr.Modulus()); !zr.IsOne() {
+		return z, ErrNotInSubgroup
+	}
+	return z, nil
+}
@@ -0,0 +1,236 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// Flag bits in the most significant byte of gnark's BN254 point encodings.
+const (
+	bn254Mask               byte = 0b11 << 6
+	bn254CompressedSmall    byte = 0b10 << 6
+	bn254CompressedLarge    byte = 0b11 << 6
+	bn254CompressedInfinity byte = 0b01 << 6
+)
+
+// The curve constants b of G1 and of the twist, recovered from the generators.
+var bn254G1B, bn254G2B = func() (fp.Element, bn254.E2) {
+	_, _, g1, g2 := bn254.Generators()
+	var b1, x3 fp.Element
+	b1.Square(&g1.Y)
+	x3.Square(&g1.X).Mul(&x3, &g1.X)
+	b1.Sub(&b1, &x3)
+
+	var b2, x3Twist bn254.E2
+	b2.Square(&g2.Y)
+	x3Twist.Square(&g2.X).Mul(&x3Twist, &g2.X)
+	b2.Sub(&b2, &x3Twist)
+	return b1, b2
+}()
+
+// MarshalBN254Scalar returns the tagged encoding of a BN254 scalar.
+func MarshalBN254Scalar(s *fr.Element) []byte {
+	b := s.Bytes()
+	return Marshal(CurveBN254, KindScalar, b[:])
+}
+
+// UnmarshalBN254Scalar parses a tagged BN254 scalar.
+func UnmarshalBN254Scalar(b []byte) (fr.Element, error) {
+	_, p, err := payload(b, CurveBN254, KindScalar)
+	if err != nil {
+		return fr.Element{}, err
+	}
+	return DecodeBN254Scalar(p)
+}
+
+// DecodeBN254Scalar parses a big-endian scalar, rejecting values not reduced
+// modulo the group order and zero.
+func DecodeBN254Scalar(b []byte) (fr.Element, error) {
+	var s fr.Element
+	if err := checkLength(b, "scalar", fr.Bytes); err != nil {
+		return s, err
+	}
+	if err := s.SetBytesCanonical(b); err != nil {
+		return s, fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	if s.IsZero() {
+		return s, ErrZero
+	}
+	return s, nil
+}
+
+// MarshalBN254G1 returns the tagged encoding of a G1 point.
+func MarshalBN254G1(p *bn254.G1Affine, compressed bool) []byte {
+	if compressed {
+		b := p.Bytes()
+		return Marshal(CurveBN254, KindG1Compressed, b[:])
+	}
+	b := p.RawBytes()
+	return Marshal(CurveBN254, KindG1Uncompressed, b[:])
+}
+
+// UnmarshalBN254G1 parses a tagged G1 point in either form.
+func UnmarshalBN254G1(b []byte) (bn254.G1Affine, error) {
+	kind, p, err := payload(b, CurveBN254, KindG1Compressed, KindG1Uncompressed)
+	if err != nil {
+		return bn254.G1Affine{}, err
+	}
+	if kind == KindG1Compressed {
+		if err := checkLength(p, "compressed G1 point", bn254.SizeOfG1AffineCompressed); err != nil {
+			return bn254.G1Affine{}, err
+		}
+	} else if err := checkLength(p, "uncompressed G1 point", bn254.SizeOfG1AffineUncompressed); err != nil {
+		return bn254.G1Affine{}, err
+	}
+	return DecodeBN254G1(p)
+}
+
+// DecodeBN254G1 parses an untagged G1 point, compressed or uncompressed
+// depending on its length, and rejects non-canonical encodings, points off
+// the curve or outside the subgroup and the point at infinity.
+func DecodeBN254G1(b []byte) (bn254.G1Affine, error) {
+	var p bn254.G1Affine
+	if err := checkLength(b, "G1 point", bn254.SizeOfG1AffineCompressed, bn254.SizeOfG1AffineUncompressed); err != nil {
+		return p, err
+	}
+	if len(b) == bn254.SizeOfG1AffineUncompressed {
+		if p.X.SetBytesCanonical(b[:fp.Bytes]) != nil || p.Y.SetBytesCanonical(b[fp.Bytes:]) != nil {
+			return p, ErrNonCanonical
+		}
+		if err := Validate(&p); err != nil {
+			return p, err
+		}
+		return p, nil
+	}
+
+	if err := bn254CompressedFlags(b[0]); err != nil {
+		return p, err
+	}
+	x := bytes.Clone(b)
+	x[0] &^= bn254Mask
+	if p.X.SetBytesCanonical(x) != nil {
+		return p, ErrNonCanonical
+	}
+	var y2 fp.Element
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bn254G1B)
+	if y2.Legendre() == -1 {
+		return p, ErrNotOnCurve
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, ErrNotInSubgroup
+	}
+	// SetBytes has checked the subgroup and the flags excluded infinity.
+	return p, nil
+}
+
+// MarshalBN254G2 returns the tagged encoding of a G2 point.
+func MarshalBN254G2(p *bn254.G2Affine, compressed bool) []byte {
+	if compressed {
+		b := p.Bytes()
+		return Marshal(CurveBN254, KindG2Compressed, b[:])
+	}
+	b := p.RawBytes()
+	return Marshal(CurveBN254, KindG2Uncompressed, b[:])
+}
+
+// UnmarshalBN254G2 parses a tagged G2 point in either form.
+func UnmarshalBN254G2(b []byte) (bn254.G2Affine, error) {
+	kind, p, err := payload(b, CurveBN254, KindG2Compressed, KindG2Uncompressed)
+	if err != nil {
+		return bn254.G2Affine{}, err
+	}
+	if kind == KindG2Compressed {
+		if err := checkLength(p, "compressed G2 point", bn254.SizeOfG2AffineCompressed); err != nil {
+			return bn254.G2Affine{}, err
+		}
+	} else if err := checkLength(p, "uncompressed G2 point", bn254.SizeOfG2AffineUncompressed); err != nil {
+		return bn254.G2Affine{}, err
+	}
+	return DecodeBN254G2(p)
+}
+
+// DecodeBN254G2 parses an untagged G2 point like DecodeBN254G1.
+func DecodeBN254G2(b []byte) (bn254.G2Affine, error) {
+	var p bn254.G2Affine
+	if err := checkLength(b, "G2 point", bn254.SizeOfG2AffineCompressed, bn254.SizeOfG2AffineUncompressed); err != nil {
+		return p, err
+	}
+	if len(b) == bn254.SizeOfG2AffineUncompressed {
+		// X.A1 | X.A0 | Y.A1 | Y.A0
+		if p.X.A1.SetBytesCanonical(b[:fp.Bytes]) != nil || p.X.A0.SetBytesCanonical(b[fp.Bytes:2*fp.Bytes]) != nil ||
+			p.Y.A1.SetBytesCanonical(b[2*fp.Bytes:3*fp.Bytes]) != nil || p.Y.A0.SetBytesCanonical(b[3*fp.Bytes:]) != nil {
+			return p, ErrNonCanonical
+		}
+		if err := Validate(&p); err != nil {
+			return p, err
+		}
+		return p, nil
+	}
+
+	if err := bn254CompressedFlags(b[0]); err != nil {
+		return p, err
+	}
+	x := bytes.Clone(b)
+	x[0] &^= bn254Mask
+	if p.X.A1.SetBytesCanonical(x[:fp.Bytes]) != nil || p.X.A0.SetBytesCanonical(x[fp.Bytes:]) != nil {
+		return p, ErrNonCanonical
+	}
+	var y2 bn254.E2
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bn254G2B)
+	if y2.Legendre() == -1 {
+		return p, ErrNotOnCurve
+	}
+	if _, err := p.SetBytes(b); err != nil {
+		return p, ErrNotInSubgroup
+	}
+	return p, nil
+}
+
+func bn254CompressedFlags(msb byte) error {
+	switch msb & bn254Mask {
+	case bn254CompressedSmall, bn254CompressedLarge:
+		return nil
+	case bn254CompressedInfinity:
+		return ErrIdentity
+	}
+	return ErrNonCanonical
+}
+
+// MarshalBN254GT returns the tagged encoding of a GT element.
+func MarshalBN254GT(z *bn254.GT) []byte {
+	b := z.Bytes()
+	return Marshal(CurveBN254, KindGT, b[:])
+}
+
+// UnmarshalBN254GT parses a tagged GT element.
+func UnmarshalBN254GT(b []byte) (bn254.GT, error) {
+	_, p, err := payload(b, CurveBN254, KindGT)
+	if err != nil {
+		return bn254.GT{}, err
+	}
+	return DecodeBN254GT(p)
+}
+
+// DecodeBN254GT parses an untagged GT element, rejecting non-canonical
+// coordinates, elements of order other than r and the identity.
+func DecodeBN254GT(b []byte) (bn254.GT, error) {
+	var z bn254.GT
+	if err := checkLength(b, "GT element", bn254.SizeOfGT); err != nil {
+		return z, err
+	}
+	if err := z.SetBytes(b); err != nil {
+		return z, fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	if z.IsOne() {
+		return z, ErrIdentity
+	}
+	var zr bn254.GT
+	if zr.Exp(z, fr.Modulus()); !zr.IsOne() {
+		return z, ErrNotInSubgroup
+	}
+	return z, nil
+}
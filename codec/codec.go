@@ -0,0 +1,146 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Version is the version byte of tagged encodings.
+const Version = 1
+
+// HeaderSize is the size of the version, curve and kind tags preceding the payload.
+const HeaderSize = 3
+
+// Curve tags the curve an encoded value belongs to.
+type Curve uint8
+
+const (
+	CurveSecp256k1 Curve = 1
+	CurveBN254     Curve = 2
+	CurveBLS12377  Curve = 3
+)
+
+var curveNames = map[Curve]string{
+	CurveSecp256k1: "secp256k1",
+	CurveBN254:     "bn254",
+	CurveBLS12377:  "bls12-377",
+}
+
+func (c Curve) String() string {
+	if name, ok := curveNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("curve(%d)", uint8(c))
+}
+
+// ParseCurve returns the tag of the curve with the given name.
+func ParseCurve(name string) (Curve, error) {
+	for c, n := range curveNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrCurve, name)
+}
+
+// Kind tags what an encoded value is and which form it is in.
+type Kind uint8
+
+const (
+	KindScalar         Kind = 1
+	KindG1Compressed   Kind = 2
+	KindG1Uncompressed Kind = 3
+	KindG2Compressed   Kind = 4
+	KindG2Uncompressed Kind = 5
+	KindGT             Kind = 6
+)
+
+var kindNames = map[Kind]string{
+	KindScalar:         "scalar",
+	KindG1Compressed:   "compressed G1 point",
+	KindG1Uncompressed: "uncompressed G1 point",
+	KindG2Compressed:   "compressed G2 point",
+	KindG2Uncompressed: "uncompressed G2 point",
+	KindGT:             "GT element",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", uint8(k))
+}
+
+var (
+	// ErrVersion is returned for encodings with an unsupported version tag.
+	ErrVersion = errors.New("unsupported encoding version")
+	// ErrCurve is returned for encodings of another or an unknown curve.
+	ErrCurve = errors.New("wrong curve")
+	// ErrKind is returned for encodings of another kind of value.
+	ErrKind = errors.New("wrong kind")
+	// ErrLength is returned when the payload has the wrong size.
+	ErrLength = errors.New("wrong length")
+	// ErrNonCanonical is returned for encodings that do not round trip, e.g.
+	// field elements not reduced modulo the field order or stray flag bits.
+	ErrNonCanonical = errors.New("non-canonical encoding")
+	// ErrNotOnCurve is returned for points that do not satisfy the curve equation.
+	ErrNotOnCurve = errors.New("point is not on the curve")
+	// ErrNotInSubgroup is returned for points or GT elements outside the prime-order subgroup.
+	ErrNotInSubgroup = errors.New("not in the prime-order subgroup")
+	// ErrIdentity is returned for the point at infinity and the GT identity.
+	ErrIdentity = errors.New("identity element")
+	// ErrZero is returned for zero private scalars.
+	ErrZero = errors.New("zero scalar")
+)
+
+// Marshal prefixes payload with the version, curve and kind tags.
+func Marshal(curve Curve, kind Kind, payload []byte) []byte {
+	b := make([]byte, 0, HeaderSize+len(payload))
+	b = append(b, Version, byte(curve), byte(kind))
+	return append(b, payload...)
+}
+
+// Unmarshal splits a tagged encoding into its tags and payload.
+func Unmarshal(b []byte) (Curve, Kind, []byte, error) {
+	if len(b) < HeaderSize {
+		return 0, 0, nil, fmt.Errorf("%w: %d bytes, missing header", ErrLength, len(b))
+	}
+	if b[0] != Version {
+		return 0, 0, nil, fmt.Errorf("%w: %d", ErrVersion, b[0])
+	}
+	curve, kind := Curve(b[1]), Kind(b[2])
+	if _, ok := curveNames[curve]; !ok {
+		return 0, 0, nil, fmt.Errorf("%w: %v", ErrCurve, curve)
+	}
+	if _, ok := kindNames[kind]; !ok {
+		return 0, 0, nil, fmt.Errorf("%w: %v", ErrKind, kind)
+	}
+	return curve, kind, b[HeaderSize:], nil
+}
+
+// payload checks the tags of b and returns its payload.
+func payload(b []byte, curve Curve, kinds ...Kind) (Kind, []byte, error) {
+	c, k, p, err := Unmarshal(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	if c != curve {
+		return 0, nil, fmt.Errorf("%w: got %v, expected %v", ErrCurve, c, curve)
+	}
+	for _, kind := range kinds {
+		if k == kind {
+			return k, p, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("%w: got %v, expected %v", ErrKind, k, kinds[0])
+}
+
+// checkLength reports ErrLength unless b has one of the given sizes.
+func checkLength(b []byte, what string, sizes ...int) error {
+	for _, size := range sizes {
+		if len(b) == size {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s of %d bytes, expected %d", ErrLength, what, len(b), sizes[0])
+}
@@ -0,0 +1,225 @@
+package codec_test
+
+import (
+	"errors"
+	"math/big"
+	"sap-go/codec"
+	"sap-go/internal/codectest"
+	"testing"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bls12377fp "github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	bls12377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	bn254fp "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	secp256k1fp "github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	secp256k1fr "github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+// vector is an encoding together with the error decoding it must produce.
+type vector struct {
+	name   string
+	decode func([]byte) error
+	input  []byte
+	want   error
+}
+
+// same adapts a decoder into one that also requires the decoded value to equal want.
+func same[T comparable](want T, decode func([]byte) (T, error)) func([]byte) error {
+	return func(b []byte) error {
+		got, err := decode(b)
+		if err == nil && got != want {
+			return errors.New("decoded value differs from the encoded one")
+		}
+		return err
+	}
+}
+
+// anyValue adapts a decoder that is only checked for its error.
+func anyValue[T any](decode func([]byte) (T, error)) func([]byte) error {
+	return func(b []byte) error {
+		_, err := decode(b)
+		return err
+	}
+}
+
+// modulusBytes returns the big-endian encoding of m in size bytes.
+func modulusBytes(m *big.Int, size int) []byte {
+	return m.FillBytes(make([]byte, size))
+}
+
+// withFlags returns b with its flag bits replaced.
+func withFlags(b []byte, mask, flags byte) []byte {
+	b[0] = b[0]&^mask | flags
+	return b
+}
+
+// runVectors decodes each vector and checks that it is accepted or rejected
+// with the expected error.
+func runVectors(t *testing.T, vectors []vector) {
+	t.Helper()
+	for _, v := range vectors {
+		if err := v.decode(v.input); !errors.Is(err, v.want) {
+			t.Errorf("%s: got %v, expected %v", v.name, err, v.want)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) { runVectors(t, headerVectors()) }
+
+func TestBN254(t *testing.T) { runVectors(t, bn254Vectors()) }
+
+func TestBLS12377(t *testing.T) { runVectors(t, bls12377Vectors()) }
+
+func TestSecp256k1(t *testing.T) { runVectors(t, secp256k1Vectors()) }
+
+func headerVectors() []vector {
+	_, _, g1, _ := bn254.Generators()
+	point := codec.MarshalBN254G1(&g1, true)
+	unmarshal := anyValue(codec.UnmarshalBN254G1)
+
+	badVersion := append([]byte{}, point...)
+	badVersion[0] = codec.Version + 1
+	unknownCurve := append([]byte{}, point...)
+	unknownCurve[1] = 0xff
+	return []vector{
+		{"empty", unmarshal, nil, codec.ErrLength},
+		{"version", unmarshal, badVersion, codec.ErrVersion},
+		{"unknown curve", unmarshal, unknownCurve, codec.ErrCurve},
+		{"other curve", unmarshal, codec.Marshal(codec.CurveBLS12377, codec.KindG1Compressed, point[codec.HeaderSize:]), codec.ErrCurve},
+		{"other kind", anyValue(codec.UnmarshalBN254G2), point, codec.ErrKind},
+		{"scalar as point", unmarshal, codec.Marshal(codec.CurveBN254, codec.KindScalar, point[codec.HeaderSize:]), codec.ErrKind},
+		{"truncated", unmarshal, point[:len(point)-1], codec.ErrLength},
+		{"trailing byte", unmarshal, append(append([]byte{}, point...), 0), codec.ErrLength},
+	}
+}
+
+// invalidVectors checks that Validate and decoding of the compressed
+// encoding both reject each crafted point with the expected error.
+func invalidVectors[T any, P interface {
+	*T
+	codec.Point
+}](prefix string, invalid []codectest.Invalid[T], decode func([]byte) error) []vector {
+	var vectors []vector
+	for i := range invalid {
+		inv := invalid[i]
+		point := P(&inv.Point)
+		validate := func([]byte) error { return codec.Validate(point) }
+		vectors = append(vectors, vector{prefix + " " + inv.Name, validate, nil, inv.Err})
+		if inv.Compressed != nil {
+			vectors = append(vectors, vector{prefix + " " + inv.Name + " compressed", decode, inv.Compressed, inv.Err})
+		}
+	}
+	return vectors
+}
+
+func bn254Vectors() []vector {
+	_, _, g1, g2 := bn254.Generators()
+	var s bn254fr.Element
+	s.SetUint64(7)
+
+	offCurve := g1
+	offCurve.Y.Add(&offCurve.Y, new(bn254fp.Element).SetOne())
+	twist := codectest.BN254G2OutsideSubgroup()
+
+	var notInGT bn254.GT
+	notInGT.C0.B0.A0.SetUint64(2)
+	var gt bn254.GT
+	gt.C0.B0.A0.SetUint64(2)
+	gt.C1.B2.A1.SetUint64(3)
+	gt = bn254.FinalExponentiation(&gt)
+	var oneGT bn254.GT
+	oneGT.SetOne()
+
+	g1Bytes, g2Bytes := g1.Bytes(), g2.Bytes()
+	offCurveRaw, twistRaw := offCurve.RawBytes(), twist.RawBytes()
+	notInGTBytes, oneGTBytes := notInGT.Bytes(), oneGT.Bytes()
+	fieldModulus := modulusBytes(bn254fp.Modulus(), bn254fp.Bytes)
+	var infinity bn254.G1Affine
+	infinityRaw := infinity.RawBytes()
+
+	decodeG1, decodeG2 := anyValue(codec.DecodeBN254G1), anyValue(codec.DecodeBN254G2)
+	vectors := []vector{
+		{"bn254 scalar", same(s, codec.UnmarshalBN254Scalar), codec.MarshalBN254Scalar(&s), nil},
+		{"bn254 zero scalar", anyValue(codec.DecodeBN254Scalar), make([]byte, bn254fr.Bytes), codec.ErrZero},
+		{"bn254 unreduced scalar", anyValue(codec.DecodeBN254Scalar), modulusBytes(bn254fr.Modulus(), bn254fr.Bytes), codec.ErrNonCanonical},
+		{"bn254 G1 compressed", same(g1, codec.UnmarshalBN254G1), codec.MarshalBN254G1(&g1, true), nil},
+		{"bn254 G1 uncompressed", same(g1, codec.UnmarshalBN254G1), codec.MarshalBN254G1(&g1, false), nil},
+		{"bn254 G2 compressed", same(g2, codec.UnmarshalBN254G2), codec.MarshalBN254G2(&g2, true), nil},
+		{"bn254 G2 uncompressed", same(g2, codec.UnmarshalBN254G2), codec.MarshalBN254G2(&g2, false), nil},
+		{"bn254 G1 identity uncompressed", decodeG1, infinityRaw[:], codec.ErrIdentity},
+		{"bn254 G1 missing flags", decodeG1, withFlags(g1Bytes[:], codec.BN254Mask, 0), codec.ErrNonCanonical},
+		{"bn254 G1 unreduced x", decodeG1, withFlags(fieldModulus, codec.BN254Mask, codec.BN254CompressedSmall), codec.ErrNonCanonical},
+		{"bn254 G1 off curve uncompressed", decodeG1, offCurveRaw[:], codec.ErrNotOnCurve},
+		{"bn254 G2 missing flags", decodeG2, withFlags(g2Bytes[:], codec.BN254Mask, 0), codec.ErrNonCanonical},
+		{"bn254 G2 outside subgroup uncompressed", decodeG2, twistRaw[:], codec.ErrNotInSubgroup},
+		{"bn254 GT", same(gt, codec.UnmarshalBN254GT), codec.MarshalBN254GT(&gt), nil},
+		{"bn254 GT identity", anyValue(codec.DecodeBN254GT), oneGTBytes[:], codec.ErrIdentity},
+		{"bn254 GT outside subgroup", anyValue(codec.DecodeBN254GT), notInGTBytes[:], codec.ErrNotInSubgroup},
+	}
+	vectors = append(vectors, invalidVectors("bn254 G1", codectest.InvalidBN254G1(), decodeG1)...)
+	return append(vectors, invalidVectors("bn254 G2", codectest.InvalidBN254G2(), decodeG2)...)
+}
+
+func bls12377Vectors() []vector {
+	_, _, g1, g2 := bls12377.Generators()
+	var s bls12377fr.Element
+	s.SetUint64(7)
+
+	curve, twist := codectest.BLS12377G1OutsideSubgroup(), codectest.BLS12377G2OutsideSubgroup()
+	var notInGT bls12377.GT
+	notInGT.C0.B0.A0.SetUint64(2)
+	var gt bls12377.GT
+	gt.C0.B0.A0.SetUint64(2)
+	gt.C1.B2.A1.SetUint64(3)
+	gt = bls12377.FinalExponentiation(&gt)
+
+	g1Bytes := g1.Bytes()
+	curveRaw, twistRaw := curve.RawBytes(), twist.RawBytes()
+	notInGTBytes := notInGT.Bytes()
+	fieldModulus := modulusBytes(bls12377fp.Modulus(), bls12377fp.Bytes)
+
+	decodeG1, decodeG2 := anyValue(codec.DecodeBLS12377G1), anyValue(codec.DecodeBLS12377G2)
+	vectors := []vector{
+		{"bls12-377 scalar", same(s, codec.UnmarshalBLS12377Scalar), codec.MarshalBLS12377Scalar(&s), nil},
+		{"bls12-377 unreduced scalar", anyValue(codec.DecodeBLS12377Scalar), modulusBytes(bls12377fr.Modulus(), bls12377fr.Bytes), codec.ErrNonCanonical},
+		{"bls12-377 G1 compressed", same(g1, codec.UnmarshalBLS12377G1), codec.MarshalBLS12377G1(&g1, true), nil},
+		{"bls12-377 G1 uncompressed", same(g1, codec.UnmarshalBLS12377G1), codec.MarshalBLS12377G1(&g1, false), nil},
+		{"bls12-377 G2 compressed", same(g2, codec.UnmarshalBLS12377G2), codec.MarshalBLS12377G2(&g2, true), nil},
+		{"bls12-377 G2 uncompressed", same(g2, codec.UnmarshalBLS12377G2), codec.MarshalBLS12377G2(&g2, false), nil},
+		{"bls12-377 G1 missing flags", decodeG1, withFlags(g1Bytes[:], codec.BLS12377Mask, 0), codec.ErrNonCanonical},
+		{"bls12-377 G1 unreduced x", decodeG1, withFlags(fieldModulus, codec.BLS12377Mask, codec.BLS12377CompressedSmall), codec.ErrNonCanonical},
+		{"bls12-377 G1 outside subgroup uncompressed", decodeG1, curveRaw[:], codec.ErrNotInSubgroup},
+		{"bls12-377 G2 outside subgroup uncompressed", decodeG2, twistRaw[:], codec.ErrNotInSubgroup},
+		{"bls12-377 GT", same(gt, codec.UnmarshalBLS12377GT), codec.MarshalBLS12377GT(&gt), nil},
+		{"bls12-377 GT outside subgroup", anyValue(codec.DecodeBLS12377GT), notInGTBytes[:], codec.ErrNotInSubgroup},
+	}
+	vectors = append(vectors, invalidVectors("bls12-377 G1", codectest.InvalidBLS12377G1(), decodeG1)...)
+	return append(vectors, invalidVectors("bls12-377 G2", codectest.InvalidBLS12377G2(), decodeG2)...)
+}
+
+func secp256k1Vectors() []vector {
+	_, g := secp256k1.Generators()
+	var s secp256k1fr.Element
+	s.SetUint64(7)
+
+	offCurve := g
+	offCurve.Y.Add(&offCurve.Y, new(secp256k1fp.Element).SetOne())
+	badPrefix := codec.EncodeSecp256k1(&g, true)
+	badPrefix[0] = 0x05
+	unreducedX := append([]byte{0x02}, modulusBytes(secp256k1fp.Modulus(), secp256k1fp.Bytes)...)
+
+	decode := anyValue(codec.DecodeSecp256k1)
+	vectors := []vector{
+		{"secp256k1 scalar", same(s, codec.UnmarshalSecp256k1Scalar), codec.MarshalSecp256k1Scalar(&s), nil},
+		{"secp256k1 unreduced scalar", anyValue(codec.DecodeSecp256k1Scalar), modulusBytes(secp256k1fr.Modulus(), secp256k1fr.Bytes), codec.ErrNonCanonical},
+		{"secp256k1 compressed", same(g, codec.UnmarshalSecp256k1), codec.MarshalSecp256k1(&g, true), nil},
+		{"secp256k1 uncompressed", same(g, codec.UnmarshalSecp256k1), codec.MarshalSecp256k1(&g, false), nil},
+		{"secp256k1 prefix", decode, badPrefix, codec.ErrNonCanonical},
+		{"secp256k1 unreduced x", decode, unreducedX, codec.ErrNonCanonical},
+		{"secp256k1 off curve uncompressed", decode, codec.EncodeSecp256k1(&offCurve, false), codec.ErrNotOnCurve},
+	}
+	return append(vectors, invalidVectors("secp256k1", codectest.InvalidSecp256k1(), decode)...)
+}
@@ -0,0 +1,9 @@
+package codec
+
+// Flag bits of the compressed encodings, for the external tests.
+const (
+	BN254Mask               = bn254Mask
+	BN254CompressedSmall    = bn254CompressedSmall
+	BLS12377Mask            = bls12377Mask
+	BLS12377CompressedSmall = bls12377CompressedSmall
+)
@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+// Sizes of SEC1 encoded secp256k1 points.
+const (
+	Secp256k1CompressedSize   = 1 + fp.Bytes
+	Secp256k1UncompressedSize = 1 + 2*fp.Bytes
+)
+
+// MarshalSecp256k1Scalar returns the tagged encoding of a secp256k1 scalar.
+func MarshalSecp256k1Scalar(s *fr.Element) []byte {
+	b := s.Bytes()
+	return Marshal(CurveSecp256k1, KindScalar, b[:])
+}
+
+// UnmarshalSecp256k1Scalar parses a tagged secp256k1 scalar.
+func UnmarshalSecp256k1Scalar(b []byte) (fr.Element, error) {
+	_, p, err := payload(b, CurveSecp256k1, KindScalar)
+	if err != nil {
+		return fr.Element{}, err
+	}
+	return DecodeSecp256k1Scalar(p)
+}
+
+// DecodeSecp256k1Scalar parses a big-endian scalar, rejecting values not
+// reduced modulo the group order and zero.
+func DecodeSecp256k1Scalar(b []byte) (fr.Element, error) {
+	var s fr.Element
+	if err := checkLength(b, "scalar", fr.Bytes); err != nil {
+		return s, err
+	}
+	if err := s.SetBytesCanonical(b); err != nil {
+		return s, fmt.Errorf("%w: %v", ErrNonCanonical, err)
+	}
+	if s.IsZero() {
+		return s, ErrZero
+	}
+	return s, nil
+}
+
+// EncodeSecp256k1 returns the SEC1 encoding of p: 0x02 or 0x03 for the
+// parity of y followed by x when compressed, 0x04 followed by x and y otherwise.
+func EncodeSecp256k1(p *secp256k1.G1Affine, compressed bool) []byte {
+	x, y := p.X.Bytes(), p.Y.Bytes()
+	if !compressed {
+		b := make([]byte, 0, Secp256k1UncompressedSize)
+		b = append(b, 0x04)
+		b = append(b, x[:]...)
+		return append(b, y[:]...)
+	}
+	prefix := byte(0x02)
+	if y[fp.Bytes-1]&1 == 1 {
+		prefix = 0x03
+	}
+	return append([]byte{prefix}, x[:]...)
+}
+
+// MarshalSecp256k1 returns the tagged SEC1 encoding of a point.
+func MarshalSecp256k1(p *secp256k1.G1Affine, compressed bool) []byte {
+	kind := KindG1Uncompressed
+	if compressed {
+		kind = KindG1Compressed
+	}
+	return Marshal(CurveSecp256k1, kind, EncodeSecp256k1(p, compressed))
+}
+
+// UnmarshalSecp256k1 parses a tagged SEC1 point in either form.
+func UnmarshalSecp256k1(b []byte) (secp256k1.G1Affine, error) {
+	kind, p, err := payload(b, CurveSecp256k1, KindG1Compressed, KindG1Uncompressed)
+	if err != nil {
+		return secp256k1.G1Affine{}, err
+	}
+	size := Secp256k1UncompressedSize
+	if kind == KindG1Compressed {
+		size = Secp256k1CompressedSize
+	}
+	if err := checkLength(p, kind.String(), size); err != nil {
+		return secp256k1.G1Affine{}, err
+	}
+	return DecodeSecp256k1(p)
+}
+
+// DecodeSecp256k1 parses an untagged SEC1 point, recovering y from x for the
+// compressed form. The point at infinity has no SEC1 encoding here and the
+// cofactor is 1, so every point on the curve is accepted.
+func DecodeSecp256k1(b []byte) (secp256k1.G1Affine, error) {
+	var p secp256k1.G1Affine
+	if err := checkLength(b, "point", Secp256k1CompressedSize, Secp256k1UncompressedSize); err != nil {
+		return p, err
+	}
+	if len(b) == Secp256k1UncompressedSize {
+		if b[0] != 0x04 {
+			return p, fmt.Errorf("%w: prefix 0x%02x", ErrNonCanonical, b[0])
+		}
+		if p.X.SetBytesCanonical(b[1:1+fp.Bytes]) != nil || p.Y.SetBytesCanonical(b[1+fp.Bytes:]) != nil {
+			return p, ErrNonCanonical
+		}
+		return p, Validate(&p)
+	}
+
+	if b[0] != 0x02 && b[0] != 0x03 {
+		return p, fmt.Errorf("%w: prefix 0x%02x", ErrNonCanonical, b[0])
+	}
+	if p.X.SetBytesCanonical(b[1:]) != nil {
+		return p, ErrNonCanonical
+	}
+	// y^2 = x^3 + 7
+	var y2, seven fp.Element
+	seven.SetUint64(7)
+	y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &seven)
+	if p.Y.Sqrt(&y2) == nil {
+		return p, ErrNotOnCurve
+	}
+	y := p.Y.Bytes()
+	if y[fp.Bytes-1]&1 != b[0]&1 {
+		p.Y.Neg(&p.Y)
+	}
+	return p, Validate(&p)
+}
@@ -0,0 +1,21 @@
+package codec
+
+// Point is implemented by the gnark affine point types of every curve.
+type Point interface {
+	IsInfinity() bool
+	IsOnCurve() bool
+	IsInSubGroup() bool
+}
+
+// Validate rejects the identity, off-curve points and points outside the subgroup.
+func Validate(p Point) error {
+	switch {
+	case p.IsInfinity():
+		return ErrIdentity
+	case !p.IsOnCurve():
+		return ErrNotOnCurve
+	case !p.IsInSubGroup():
+		return ErrNotInSubgroup
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sap-go/protocol"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// Version is the version of the binary format written by Write.
+const Version = 1
+
+// magic identifies a dataset file.
+var magic = [4]byte{'S', 'A', 'P', 'D'}
+
+// readChunk is the number of announcements Read allocates at a time.
+const readChunk = 4096
+
+// ErrFormat is returned when a file is not a valid dataset.
+var ErrFormat = errors.New("invalid dataset file")
+
+// Header describes how a dataset was generated and who it is addressed to.
+// The recipient's private keys are stored so the dataset can be scanned on
+// its own; datasets are benchmark fixtures, not wallets. Seed is the seed the
+// announcements were generated from.
+type Header struct {
+	Protocol    string
+	Curve       string
+	Seed        int64
+	Owned       int
+	SpendingKey []byte
+	ViewingKey  []byte
+}
+
+// Dataset is a set of announcements for one protocol, some of them addressed
+// to the recipient in the header.
+type Dataset struct {
+	Header
+	Announcements []protocol.Announcement
+}
+
+// FileName returns the canonical file name of a dataset.
+func FileName(protocol, curve string, announcements int) string {
+	return fmt.Sprintf("dataset_%s_%s_%d.sap", protocol, curve, announcements)
+}
+
+// Generate builds a dataset for r with one announcement per position. Owned
+// positions are sent to r, the others are random announcements. The
+// ephemeral scalars, view tags and addresses are drawn from a DRBG seeded by
+// seed and the position, so the same recipient, positions and seed give the
+// same dataset although the announcements are generated on all CPUs.
+func Generate(p protocol.Protocol, r protocol.Recipient, positions []bool, seed int64) (*Dataset, error) {
+	d := &Dataset{
+		Header: Header{
+			Protocol:    p.Name(),
+			Curve:       p.Curve(),
+			Seed:        seed,
+			SpendingKey: r.SpendingKey(),
+			ViewingKey:  r.ViewingKey(),
+		},
+		Announcements: make([]protocol.Announcement, len(positions)),
+	}
+	for _, owned := range positions {
+		if owned {
+			d.Owned++
+		}
+	}
+
+	meta := r.MetaAddress()
+	var next atomic.Int64
+	errs := make([]error, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for w := range errs {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(positions); i = int(next.Add(1) - 1) {
+				seeded := protocol.WithRandom(p, drbg(seed, "announcement", uint64(i)))
+				var err error
+				if positions[i] {
+					d.Announcements[i], err = seeded.Send(meta)
+				} else {
+					d.Announcements[i], err = seeded.RandomAnnouncement()
+				}
+				if err != nil {
+					errs[w] = err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GenerateRecipient creates the recipient of p that seed determines.
+func GenerateRecipient(p protocol.Protocol, seed int64) (protocol.Recipient, error) {
+	return protocol.WithRandom(p, drbg(seed, "recipient", 0)).GenerateRecipient()
+}
+
+// drbg returns the SHAKE256 stream of seed for one use and index.
+func drbg(seed int64, use string, index uint64) io.Reader {
+	h := sha3.NewShake256()
+	h.Write([]byte("sap-dataset/" + use))
+	binary.Write(h, binary.LittleEndian, seed)
+	binary.Write(h, binary.LittleEndian, index)
+	return h
+}
+
+// Protocol returns the protocol the dataset was generated for.
+func (d *Dataset) Protocol() (protocol.Protocol, error) {
+	return protocol.Lookup(d.Header.Protocol, d.Curve)
+}
+
+// Recipient restores the recipient the owned announcements are addressed to.
+func (d *Dataset) Recipient(p protocol.Protocol) (protocol.Recipient, error) {
+	if err := d.check(p); err != nil {
+		return nil, err
+	}
+	return p.NewRecipient(d.SpendingKey, d.ViewingKey)
+}
+
+// Decode decodes the ephemeral keys of all announcements.
+func (d *Dataset) Decode(p protocol.Protocol) ([]protocol.Decoded, error) {
+	if err := d.check(p); err != nil {
+		return nil, err
+	}
+	decoded := make([]protocol.Decoded, len(d.Announcements))
+	for i, ann := range d.Announcements {
+		var err error
+		if decoded[i], err = protocol.Decode(p, ann); err != nil {
+			return nil, fmt.Errorf("announcement %d: %w", i, err)
+		}
+	}
+	return decoded, nil
+}
+
+func (d *Dataset) check(p protocol.Protocol) error {
+	if p.Name() != d.Header.Protocol || p.Curve() != d.Curve {
+		return fmt.Errorf("dataset is for %s/%s, not %s", d.Header.Protocol, d.Curve, protocol.ID(p))
+	}
+	return nil
+}
+
+// Write encodes d in the binary dataset format. All integers are little endian:
+//
+//	magic "SAPD", version u8
+//	protocol, curve, spending key, viewing key: u8 length + bytes each
+//	seed i64, owned u32, ephemeral key size u16, announcement count u32
+//	announcements: ephemeral key, view tag u8, 20 byte address
+func Write(w io.Writer, d *Dataset) error {
+	ephemeralSize := 0
+	if len(d.Announcements) > 0 {
+		ephemeralSize = len(d.Announcements[0].Ephemeral)
+	}
+	for i, ann := range d.Announcements {
+		if len(ann.Ephemeral) != ephemeralSize {
+			return fmt.Errorf("announcement %d: ephemeral key size %d, expected %d", i, len(ann.Ephemeral), ephemeralSize)
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.Write(magic[:])
+	bw.WriteByte(Version)
+	for _, field := range [][]byte{[]byte(d.Header.Protocol), []byte(d.Curve), d.SpendingKey, d.ViewingKey} {
+		if len(field) > 255 {
+			return fmt.Errorf("header field too long (%d bytes)", len(field))
+		}
+		bw.WriteByte(byte(len(field)))
+		bw.Write(field)
+	}
+	binary.Write(bw, binary.LittleEndian, d.Seed)
+	binary.Write(bw, binary.LittleEndian, uint32(d.Owned))
+	binary.Write(bw, binary.LittleEndian, uint16(ephemeralSize))
+	binary.Write(bw, binary.LittleEndian, uint32(len(d.Announcements)))
+	for _, ann := range d.Announcements {
+		bw.Write(ann.Ephemeral)
+		bw.WriteByte(ann.ViewTag)
+		bw.Write(ann.Address[:])
+	}
+	return bw.Flush()
+}
+
+// Layout describes the fixed-size announcement records following the header.
+type Layout struct {
+	// HeaderSize is the offset of the first record.
+	HeaderSize    int64
+	EphemeralSize int
+	Count         int
+}
+
+// RecordSize returns the size of one announcement record in bytes.
+func (l Layout) RecordSize() int {
+	return l.EphemeralSize + 1 + protocol.AddressLength
+}
+
+// Record parses the announcement record b. The ephemeral key aliases b.
+func (l Layout) Record(b []byte) protocol.Announcement {
+	ann := protocol.Announcement{Ephemeral: b[:l.EphemeralSize:l.EphemeralSize], ViewTag: b[l.EphemeralSize]}
+	copy(ann.Address[:], b[l.EphemeralSize+1:])
+	return ann
+}
+
+// ReadHeader reads the header of a dataset, leaving r at the first record.
+func ReadHeader(r io.Reader) (Header, Layout, error) {
+	var fileMagic [4]byte
+	if _, err := io.ReadFull(r, fileMagic[:]); err != nil || fileMagic != magic {
+		return Header{}, Layout{}, fmt.Errorf("%w: bad magic", ErrFormat)
+	}
+	var version [1]byte
+	if _, err := io.ReadFull(r, version[:]); err != nil {
+		return Header{}, Layout{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if version[0] != Version {
+		return Header{}, Layout{}, fmt.Errorf("%w: unsupported version %d", ErrFormat, version[0])
+	}
+	headerSize := int64(len(magic) + len(version))
+
+	var fields [4][]byte
+	for i := range fields {
+		var n [1]byte
+		if _, err := io.ReadFull(r, n[:]); err != nil {
+			return Header{}, Layout{}, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		fields[i] = make([]byte, n[0])
+		if _, err := io.ReadFull(r, fields[i]); err != nil {
+			return Header{}, Layout{}, fmt.Errorf("%w: %v", ErrFormat, err)
+		}
+		headerSize += 1 + int64(n[0])
+	}
+	var fixed struct {
+		Seed          int64
+		Owned         uint32
+		EphemeralSize uint16
+		Count         uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &fixed); err != nil {
+		return Header{}, Layout{}, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	headerSize += int64(binary.Size(fixed))
+
+	header := Header{
+		Protocol:    string(fields[0]),
+		Curve:       string(fields[1]),
+		SpendingKey: fields[2],
+		ViewingKey:  fields[3],
+		Seed:        fixed.Seed,
+		Owned:       int(fixed.Owned),
+	}
+	layout := Layout{HeaderSize: headerSize, EphemeralSize: int(fixed.EphemeralSize), Count: int(fixed.Count)}
+	return header, layout, nil
+}
+
+// Read decodes a dataset written by Write.
+func Read(r io.Reader) (*Dataset, error) {
+	br := bufio.NewReader(r)
+	header, layout, err := ReadHeader(br)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dataset{
+		Header: header,
+		// the count is untrusted until the records are read, grow in chunks
+		Announcements: make([]protocol.Announcement, 0, min(layout.Count, readChunk)),
+	}
+	var records []byte
+	for i := 0; i < layout.Count; i++ {
+		if len(records) < layout.RecordSize() {
+			records = make([]byte, readChunk*layout.RecordSize())
+		}
+		record := records[:layout.RecordSize()]
+		records = records[layout.RecordSize():]
+		if _, err := io.ReadFull(br, record); err != nil {
+			return nil, fmt.Errorf("%w: announcement %d: %v", ErrFormat, i, err)
+		}
+		d.Announcements = append(d.Announcements, layout.Record(record))
+	}
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, fmt.Errorf("%w: trailing data", ErrFormat)
+	}
+	return d, nil
+}
+
+// Save writes d to the file at path.
+func Save(path string, d *Dataset) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, d); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads the dataset file at path.
+func Load(path string) (*Dataset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
@@ -0,0 +1,133 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"sap-go/protocol"
+	"testing"
+)
+
+// positions marks every third of n announcements as owned.
+func positions(n int) []bool {
+	owned := make([]bool, n)
+	for i := range owned {
+		owned[i] = i%3 == 1
+	}
+	return owned
+}
+
+// generate writes the dataset of p that seed determines.
+func generate(t *testing.T, p protocol.Protocol, seed int64) []byte {
+	r, err := GenerateRecipient(p, seed)
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	d, err := Generate(p, r, positions(12), seed)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, d); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestReproducible generates the dataset of every protocol twice from one
+// seed and checks that the files are identical, that another seed gives a
+// different file and that the owned announcements are found.
+func TestReproducible(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testReproducible(t, p) })
+	}
+}
+
+func testReproducible(t *testing.T, p protocol.Protocol) {
+	first, second := generate(t, p, 42), generate(t, p, 42)
+	if !bytes.Equal(first, second) {
+		t.Fatal("two datasets of seed 42 differ")
+	}
+	if bytes.Equal(first, generate(t, p, 43)) {
+		t.Fatal("datasets of seeds 42 and 43 are identical")
+	}
+
+	d, err := Read(bytes.NewReader(first))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	r, err := d.Recipient(p)
+	if err != nil {
+		t.Fatalf("Recipient: %v", err)
+	}
+	decoded, err := d.Decode(p)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	for i, ann := range decoded {
+		if ok, err := p.Check(r, ann); err != nil || ok != positions(len(decoded))[i] {
+			t.Fatalf("announcement %d: Check %v (err: %v), owned %v", i, ok, err, positions(len(decoded))[i])
+		}
+	}
+}
+
+// TestFormat round trips the dataset of every protocol through Write and
+// Read, and checks that ReadHeader and Read reject a bad magic, an unknown
+// version, a truncated body and a count that disagrees with the body.
+func TestFormat(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testFormat(t, p) })
+	}
+}
+
+func testFormat(t *testing.T, p protocol.Protocol) {
+	file := generate(t, p, 7)
+	d, err := Read(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if d.Header.Protocol != p.Name() || d.Curve != p.Curve() || d.Seed != 7 || d.Owned != 4 || len(d.Announcements) != 12 {
+		t.Fatalf("Read: got header %+v with %d announcements", d.Header, len(d.Announcements))
+	}
+	var again bytes.Buffer
+	if err := Write(&again, d); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if !bytes.Equal(again.Bytes(), file) {
+		t.Fatal("Write of the read dataset differs from the original file")
+	}
+
+	_, layout, err := ReadHeader(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+	if layout.Count != 12 || layout.HeaderSize+int64(layout.Count*layout.RecordSize()) != int64(len(file)) {
+		t.Fatalf("ReadHeader: layout %+v does not describe a %d byte file", layout, len(file))
+	}
+	// withCount replaces the announcement count, the last header field.
+	withCount := func(count byte) []byte {
+		b := bytes.Clone(file)
+		b[layout.HeaderSize-4] = count
+		return b
+	}
+	badMagic := bytes.Clone(file)
+	badMagic[0] = 'X'
+	badVersion := bytes.Clone(file)
+	badVersion[len(magic)] = Version + 1
+	for name, b := range map[string][]byte{
+		"bad magic":        badMagic,
+		"unknown version":  badVersion,
+		"truncated header": file[:layout.HeaderSize-1],
+		"truncated body":   file[:len(file)-1],
+		"count too large":  withCount(13),
+		"count too small":  withCount(11),
+	} {
+		if _, err := Read(bytes.NewReader(b)); !errors.Is(err, ErrFormat) {
+			t.Errorf("Read: %s: got %v, expected %v", name, err, ErrFormat)
+		}
+	}
+	for name, b := range map[string][]byte{"bad magic": badMagic, "unknown version": badVersion, "truncated header": file[:layout.HeaderSize-1]} {
+		if _, _, err := ReadHeader(bytes.NewReader(b)); !errors.Is(err, ErrFormat) {
+			t.Errorf("ReadHeader: %s: got %v, expected %v", name, err, ErrFormat)
+		}
+	}
+}
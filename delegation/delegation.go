@@ -0,0 +1,113 @@
+package delegation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sap-go/keystore"
+	"sap-go/protocol"
+)
+
+// Version is the version of the credential format written by Export.
+const Version = 1
+
+var (
+	// ErrFormat is returned when a credential is malformed.
+	ErrFormat = errors.New("invalid viewing credential")
+	// ErrMismatch is returned when the viewing key does not belong to the
+	// credential's meta address.
+	ErrMismatch = errors.New("viewing key does not match the meta address")
+)
+
+// Credential lets a third party scan for a recipient's announcements and
+// compute their stealth addresses without being able to spend them. It holds
+// the meta address and the viewing private key v, never the spending key k.
+//
+// A stealth private key is k plus a scalar the holder of v can compute, so
+// the stealth private keys of found payments must not be shared with the
+// delegate either.
+type Credential struct {
+	Version     int                  `json:"version"`
+	Protocol    string               `json:"protocol"`
+	Curve       string               `json:"curve"`
+	MetaAddress keystore.MetaAddress `json:"metaAddress"`
+	ViewingKey  keystore.Bytes       `json:"viewingKey"`
+}
+
+// Export creates the viewing credential of r, which may be view only.
+func Export(p protocol.Protocol, r protocol.Recipient) *Credential {
+	meta := r.MetaAddress()
+	return &Credential{
+		Version:     Version,
+		Protocol:    p.Name(),
+		Curve:       p.Curve(),
+		MetaAddress: keystore.MetaAddress{Spend: meta.Spend, View: meta.View},
+		ViewingKey:  r.ViewingKey(),
+	}
+}
+
+// Open restores the view-only recipient of c and checks that its viewing key
+// matches the meta address.
+func (c *Credential) Open() (protocol.Protocol, protocol.Recipient, error) {
+	if c.Version != Version {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrFormat, c.Version)
+	}
+	p, err := protocol.Lookup(c.Protocol, c.Curve)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	r, err := p.NewViewOnlyRecipient(c.MetaAddress.Spend, c.ViewingKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if !bytes.Equal(r.MetaAddress().View, c.MetaAddress.View) {
+		return nil, nil, ErrMismatch
+	}
+	return p, r, nil
+}
+
+// Write encodes c as indented JSON.
+func Write(w io.Writer, c *Credential) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
+
+// Read decodes a credential written by Write.
+func Read(r io.Reader) (*Credential, error) {
+	var c Credential
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return &c, nil
+}
+
+// Save writes c to a new file at path that only the owner can read. The
+// viewing key reveals every payment to the recipient, so the file is as
+// sensitive as the payments' privacy.
+func Save(path string, c *Credential) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, c); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads the credential file at path.
+func Load(path string) (*Credential, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
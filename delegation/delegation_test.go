@@ -0,0 +1,100 @@
+package delegation
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sap-go/protocol"
+	"testing"
+)
+
+// TestDelegation exports the viewing credential of a fresh recipient and
+// checks that the delegate holding it finds a payment and computes its
+// stealth address, while the credential carries no spending key and the
+// delegate cannot derive the stealth private key.
+func TestDelegation(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testDelegation(t, p) })
+	}
+}
+
+func testDelegation(t *testing.T, p protocol.Protocol) {
+	owner, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, Export(p, owner)); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	exported := buf.Bytes()
+	spendingKey := owner.SpendingKey()
+	if bytes.Contains(exported, spendingKey) || bytes.Contains(exported, []byte(hex.EncodeToString(spendingKey))) {
+		t.Fatal("Export: credential contains the spending key")
+	}
+	c, err := Read(bytes.NewReader(exported))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	q, delegate, err := c.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if protocol.ID(q) != protocol.ID(p) {
+		t.Fatalf("Open: got protocol %s", protocol.ID(q))
+	}
+	if delegate.SpendingKey() != nil {
+		t.Fatal("Open: delegate holds a spending key")
+	}
+
+	ann, err := p.Send(owner.MetaAddress())
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	decoded, err := protocol.Decode(p, ann)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if ok, err := p.Check(delegate, decoded); err != nil || !ok {
+		t.Fatalf("Check: delegate did not find the payment (err: %v)", err)
+	}
+	stealthPublicKey, err := p.StealthPublicKey(delegate, decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("StealthPublicKey: %v", err)
+	}
+	if protocol.AddressFromPublicKey(stealthPublicKey) != ann.Address {
+		t.Fatal("StealthPublicKey: delegate computed a different stealth address")
+	}
+
+	if _, err := p.Derive(delegate, decoded.Ephemeral); !errors.Is(err, protocol.ErrViewOnly) {
+		t.Fatalf("Derive: delegate: got %v, expected %v", err, protocol.ErrViewOnly)
+	}
+	// Whatever the delegate tries as the spending key, it is not the owner's.
+	for name, guess := range map[string][]byte{"viewing key": c.ViewingKey, "spending public key": c.MetaAddress.Spend} {
+		if forged, err := p.NewRecipient(guess, c.ViewingKey); err == nil && bytes.Equal(forged.MetaAddress().Spend, c.MetaAddress.Spend) {
+			t.Fatalf("NewRecipient: the %s restores the spending key", name)
+		}
+	}
+	privateKey, err := p.Derive(owner, decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("Derive: owner: %v", err)
+	}
+	if bytes.Contains(exported, privateKey) {
+		t.Fatal("Export: credential contains the stealth private key")
+	}
+
+	other, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	swapped := *c
+	swapped.ViewingKey = other.ViewingKey()
+	if _, _, err := swapped.Open(); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("Open: foreign viewing key: got %v, expected %v", err, ErrMismatch)
+	}
+	swapped = *c
+	swapped.Version = Version + 1
+	if _, _, err := swapped.Open(); !errors.Is(err, ErrFormat) {
+		t.Fatalf("Open: unknown version: got %v, expected %v", err, ErrFormat)
+	}
+}
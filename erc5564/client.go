@@ -0,0 +1,136 @@
+package erc5564
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sap-go/indexer"
+	"sync/atomic"
+)
+
+// maxResponse bounds the size of a JSON-RPC response body.
+const maxResponse = 64 << 20
+
+// Client is a minimal Ethereum JSON-RPC client for the calls the ingester
+// makes.
+type Client struct {
+	URL  string
+	HTTP *http.Client
+
+	id atomic.Uint64
+}
+
+// NewClient creates a client for the JSON-RPC endpoint at url.
+func NewClient(url string) *Client {
+	return &Client{URL: url, HTTP: http.DefaultClient}
+}
+
+// RPCError is an error returned by the JSON-RPC endpoint.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type response struct {
+	ID     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// call invokes method with params and decodes its result into result.
+func (c *Client) call(ctx context.Context, result any, method string, params ...any) error {
+	id := c.id.Add(1)
+	body, err := json.Marshal(request{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: HTTP %s", method, resp.Status)
+	}
+	var r response
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponse)).Decode(&r); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if r.Error != nil {
+		return fmt.Errorf("%s: %w", method, r.Error)
+	}
+	if r.ID != id {
+		return fmt.Errorf("%s: response id %d, expected %d", method, r.ID, id)
+	}
+	if len(r.Result) == 0 || string(r.Result) == "null" {
+		return fmt.Errorf("%s: %w", method, ErrNotFound)
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	return nil
+}
+
+// ErrNotFound is returned when the endpoint has no result, such as for a
+// block it does not know.
+var ErrNotFound = errors.New("not found")
+
+// BlockNumber returns the number of the latest block.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var s string
+	if err := c.call(ctx, &s, "eth_blockNumber"); err != nil {
+		return 0, err
+	}
+	return decodeQuantity(s)
+}
+
+// BlockHash returns the hash of the block with the given number.
+func (c *Client) BlockHash(ctx context.Context, block uint64) (indexer.Hash, error) {
+	var header struct {
+		Hash string `json:"hash"`
+	}
+	if err := c.call(ctx, &header, "eth_getBlockByNumber", encodeQuantity(block), false); err != nil {
+		return indexer.Hash{}, err
+	}
+	hash, err := decodeHash(header.Hash)
+	if err != nil {
+		return indexer.Hash{}, fmt.Errorf("eth_getBlockByNumber: block %d: %w", block, err)
+	}
+	return hash, nil
+}
+
+// Logs returns the Announcement logs emitted by contract in blocks from to
+// to, inclusive.
+func (c *Client) Logs(ctx context.Context, contract string, from, to uint64) ([]Log, error) {
+	filter := map[string]any{
+		"fromBlock": encodeQuantity(from),
+		"toBlock":   encodeQuantity(to),
+		"address":   contract,
+		"topics":    []string{indexer.Hash(AnnouncementTopic).String()},
+	}
+	var logs []Log
+	if err := c.call(ctx, &logs, "eth_getLogs", filter); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
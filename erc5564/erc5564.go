@@ -0,0 +1,289 @@
+package erc5564
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sap-go/annfile"
+	"sap-go/indexer"
+	"sap-go/protocol"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// AnnouncementTopic is the first topic of the Announcement event:
+	// keccak256("Announcement(uint256,address,address,bytes,bytes)").
+	AnnouncementTopic = keccak256("Announcement(uint256,address,address,bytes,bytes)")
+	// AnnounceSelector is the function selector of
+	// announce(uint256,address,bytes,bytes) on the ERC5564Announcer.
+	AnnounceSelector = selector("announce(uint256,address,bytes,bytes)")
+)
+
+var (
+	// ErrUnknownScheme is returned for scheme IDs without a protocol.
+	ErrUnknownScheme = errors.New("unknown ERC-5564 scheme ID")
+	// ErrLog is returned for logs that are not well-formed Announcement events.
+	ErrLog = errors.New("malformed Announcement log")
+	// ErrSchemeSecp256k1 is returned for announcements of the ERC's
+	// secp256k1 scheme, whose derivation no protocol implements.
+	ErrSchemeSecp256k1 = errors.New("ERC-5564 secp256k1 scheme 1 is not supported")
+)
+
+// SchemeSecp256k1 is the ERC's registered secp256k1 scheme. It hashes with
+// Keccak-256 and pays to Ethereum addresses, while every protocol here
+// hashes with SHA-256, so no protocol is published under it.
+const SchemeSecp256k1 = 1
+
+// schemes assigns ERC-5564 scheme IDs. None of the protocols matches a
+// registered scheme, so they use IDs the ERC has not registered.
+var schemes = []struct {
+	id uint64
+	p  protocol.Protocol
+}{
+	{2, protocol.ECPDKSAPBN254()},
+	{3, protocol.ECPDKSAPBLS12377()},
+	{4, protocol.KeyChangeBN254()},
+	{5, protocol.SingleKeyBN254()},
+	{6, protocol.DKSAPSecp256k1()},
+}
+
+// SchemeID returns the scheme ID announcements of p are published under.
+func SchemeID(p protocol.Protocol) (uint64, error) {
+	for _, s := range schemes {
+		if protocol.ID(s.p) == protocol.ID(p) {
+			return s.id, nil
+		}
+	}
+	return 0, fmt.Errorf("%w for %s", ErrUnknownScheme, protocol.ID(p))
+}
+
+// Scheme returns the protocol of a scheme ID.
+func Scheme(id uint64) (protocol.Protocol, error) {
+	for _, s := range schemes {
+		if s.id == id {
+			return s.p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %d", ErrUnknownScheme, id)
+}
+
+func keccak256(s string) [32]byte {
+	var out [32]byte
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(s))
+	h.Sum(out[:0])
+	return out
+}
+
+func selector(signature string) [4]byte {
+	hash := keccak256(signature)
+	return [4]byte(hash[:4])
+}
+
+// Log is an Ethereum log as returned by eth_getLogs.
+type Log struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	BlockHash       string   `json:"blockHash"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+	Removed         bool     `json:"removed"`
+}
+
+// DecodeLog decodes an Announcement event into an index entry. The view tag
+// is the first byte of the metadata. Announcements of scheme 1 are rejected
+// with ErrSchemeSecp256k1: scanning them with DKSAP's SHA-256 derivation
+// would never find their payments.
+func DecodeLog(l Log) (indexer.Entry, error) {
+	var e indexer.Entry
+	if len(l.Topics) != 4 {
+		return e, fmt.Errorf("%w: %d topics", ErrLog, len(l.Topics))
+	}
+	topics := make([][32]byte, len(l.Topics))
+	for i, t := range l.Topics {
+		b, err := decodeHex(t)
+		if err != nil || len(b) != 32 {
+			return e, fmt.Errorf("%w: topic %d", ErrLog, i)
+		}
+		topics[i] = [32]byte(b)
+	}
+	if topics[0] != AnnouncementTopic {
+		return e, fmt.Errorf("%w: not an Announcement event", ErrLog)
+	}
+	id := new(big.Int).SetBytes(topics[1][:])
+	if !id.IsUint64() {
+		return e, fmt.Errorf("%w %s", ErrUnknownScheme, id)
+	}
+	if id.Uint64() == SchemeSecp256k1 {
+		return e, ErrSchemeSecp256k1
+	}
+	p, err := Scheme(id.Uint64())
+	if err != nil {
+		return e, err
+	}
+
+	data, err := decodeHex(l.Data)
+	if err != nil {
+		return e, fmt.Errorf("%w: data: %v", ErrLog, err)
+	}
+	ephemeral, err := abiBytes(data, 0)
+	if err != nil {
+		return e, fmt.Errorf("%w: ephemeral key: %v", ErrLog, err)
+	}
+	metadata, err := abiBytes(data, 1)
+	if err != nil {
+		return e, fmt.Errorf("%w: metadata: %v", ErrLog, err)
+	}
+	if len(metadata) < 1 {
+		return e, fmt.Errorf("%w: metadata has no view tag", ErrLog)
+	}
+
+	if e.Block, err = decodeQuantity(l.BlockNumber); err != nil {
+		return e, fmt.Errorf("%w: block number: %v", ErrLog, err)
+	}
+	logIndex, err := decodeQuantity(l.LogIndex)
+	if err != nil || logIndex > 1<<32-1 {
+		return e, fmt.Errorf("%w: log index %q", ErrLog, l.LogIndex)
+	}
+	e.LogIndex = uint32(logIndex)
+	if e.TxHash, err = decodeHash(l.TransactionHash); err != nil {
+		return e, fmt.Errorf("%w: transaction hash: %v", ErrLog, err)
+	}
+	var address protocol.Address
+	copy(address[:], topics[2][32-protocol.AddressLength:])
+	e.Record = annfile.NewRecord(p, protocol.Announcement{Ephemeral: ephemeral, ViewTag: metadata[0], Address: address})
+	return e, nil
+}
+
+// abiBytes returns the i-th dynamic bytes argument of ABI encoded data.
+func abiBytes(data []byte, i uint64) ([]byte, error) {
+	offset, err := abiWord(data, 32*i)
+	if err != nil {
+		return nil, err
+	}
+	length, err := abiWord(data, offset)
+	if err != nil {
+		return nil, err
+	}
+	if length > uint64(len(data)) || offset+32+length > uint64(len(data)) {
+		return nil, errors.New("out of bounds")
+	}
+	return data[offset+32 : offset+32+length], nil
+}
+
+// abiWord reads the 32-byte word at offset as an integer that fits in the data.
+func abiWord(data []byte, offset uint64) (uint64, error) {
+	if offset > uint64(len(data)) || uint64(len(data))-offset < 32 {
+		return 0, errors.New("out of bounds")
+	}
+	word := data[offset : offset+32]
+	for _, b := range word[:24] {
+		if b != 0 {
+			return 0, errors.New("out of bounds")
+		}
+	}
+	return binary.BigEndian.Uint64(word[24:]), nil
+}
+
+// FormatMetaAddress returns meta as an ERC-5564 stealth meta-address,
+// st:<chain>:0x<spending public key><viewing public key>.
+func FormatMetaAddress(chain string, meta protocol.MetaAddress) string {
+	return "st:" + chain + ":0x" + hex.EncodeToString(meta.Bytes())
+}
+
+// ParseMetaAddress parses a stealth meta-address of p in the format of
+// FormatMetaAddress, or its keys as bare 0x prefixed hex.
+func ParseMetaAddress(p protocol.Protocol, s string) (protocol.MetaAddress, error) {
+	if strings.HasPrefix(s, "st:") {
+		parts := strings.SplitN(s, ":", 3)
+		if len(parts) != 3 || parts[1] == "" {
+			return protocol.MetaAddress{}, fmt.Errorf("invalid stealth meta-address %q", s)
+		}
+		s = parts[2]
+	}
+	b, err := decodeHex(s)
+	if err != nil {
+		return protocol.MetaAddress{}, fmt.Errorf("invalid stealth meta-address: %w", err)
+	}
+	return protocol.ParseMetaAddress(p, b)
+}
+
+// EncodeAnnounce returns the calldata of announce(schemeId, stealthAddress,
+// ephemeralPubKey, metadata) on the ERC5564Announcer for ann, with the view
+// tag as the only metadata byte.
+func EncodeAnnounce(p protocol.Protocol, ann protocol.Announcement) ([]byte, error) {
+	id, err := SchemeID(p)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral := abiPadded(ann.Ephemeral)
+	head := make([]byte, 4*32)
+	binary.BigEndian.PutUint64(head[24:], id)
+	copy(head[64-protocol.AddressLength:64], ann.Address[:])
+	binary.BigEndian.PutUint64(head[88:], 4*32)
+	binary.BigEndian.PutUint64(head[120:], uint64(4*32+len(ephemeral)))
+
+	calldata := append(AnnounceSelector[:], head...)
+	calldata = append(calldata, ephemeral...)
+	return append(calldata, abiPadded([]byte{ann.ViewTag})...), nil
+}
+
+// abiPadded encodes b as the tail of a dynamic bytes argument: its length,
+// then b padded to a multiple of 32 bytes.
+func abiPadded(b []byte) []byte {
+	out := make([]byte, 32+(len(b)+31)/32*32)
+	binary.BigEndian.PutUint64(out[24:32], uint64(len(b)))
+	copy(out[32:], b)
+	return out
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("missing 0x prefix in %q", s)
+	}
+	return hex.DecodeString(s[2:])
+}
+
+func decodeHash(s string) (indexer.Hash, error) {
+	b, err := decodeHex(s)
+	if err != nil {
+		return indexer.Hash{}, err
+	}
+	if len(b) != len(indexer.Hash{}) {
+		return indexer.Hash{}, fmt.Errorf("hash of %d bytes", len(b))
+	}
+	return indexer.Hash(b), nil
+}
+
+// decodeQuantity parses a JSON-RPC hex quantity.
+func decodeQuantity(s string) (uint64, error) {
+	if !strings.HasPrefix(s, "0x") || len(s) < 3 || len(s) > 18 {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	var n uint64
+	for _, c := range s[2:] {
+		var d uint64
+		switch {
+		case c >= '0' && c <= '9':
+			d = uint64(c - '0')
+		case c >= 'a' && c <= 'f':
+			d = uint64(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			d = uint64(c-'A') + 10
+		default:
+			return 0, fmt.Errorf("invalid quantity %q", s)
+		}
+		n = n<<4 | d
+	}
+	return n, nil
+}
+
+func encodeQuantity(n uint64) string {
+	return fmt.Sprintf("0x%x", n)
+}
@@ -0,0 +1,407 @@
+package erc5564
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sap-go/indexer"
+	"sap-go/protocol"
+	"sync"
+	"testing"
+)
+
+// TestSignatures checks the event topic and function selector against the
+// ERC-5564 contracts.
+func TestSignatures(t *testing.T) {
+	if hex.EncodeToString(AnnouncementTopic[:]) != "5f0eab8057630ba7676c49b4f21a0231414e79474595be8e4c432fbf6bf0f4e7" {
+		t.Error("AnnouncementTopic differs from the ERC-5564 event signature hash")
+	}
+	if hex.EncodeToString(AnnounceSelector[:]) != "4d1f9583" {
+		t.Error("AnnounceSelector differs from the ERC5564Announcer's announce")
+	}
+}
+
+// TestIngest ingests the announcements of a mock chain served over JSON-RPC
+// into a fresh index, in pages smaller than the endpoint's range limit. It
+// checks that removed, foreign and malformed logs and those of the ERC's
+// secp256k1 scheme are skipped, that a recipient's payments are found and
+// that a reorganisation of the latest blocks rewinds the index and the scan
+// cursor to the fork point.
+func TestIngest(t *testing.T) {
+	ix, err := indexer.Open(filepath.Join(t.TempDir(), "index.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+
+	p := protocol.ECPDKSAPBN254()
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &mockChain{contract: "0x55649E01B5Df198D18D95b5cc5051630cfD45564", maxRange: 7}
+	if err := chain.extend(30, 0, r.MetaAddress(), map[uint64]bool{3: true, 12: true, 27: true}); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(chain)
+	defer server.Close()
+
+	ctx := context.Background()
+	in := &Ingester{Client: NewClient(server.URL), Index: ix, Contract: chain.contract, Start: 1, PageSize: 7, Confirmations: 2}
+	stats, err := in.Sync(ctx)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	// blocks 1 to 27 hold one announcement each, the scheme 1 log and the
+	// three malformed logs
+	if stats.Blocks != 27 || stats.Stored != 27 || stats.Skipped != 4 || stats.Secp256k1 != 1 || stats.Reorgs != 0 {
+		t.Fatalf("Sync: got %+v, expected 27 blocks with 27 stored and 4 skipped logs, 1 of scheme 1", stats)
+	}
+	if cursor, err := ix.Cursor(CursorName(chain.contract)); err != nil || cursor.Block != 28 {
+		t.Fatalf("ingestion cursor: got %v (err: %v), expected block 28", cursor, err)
+	}
+	if err := chain.checkIndex(ix, 1, 27); err != nil {
+		t.Fatal(err)
+	}
+	if found, _, err := ix.Scan("r", p, r, 2, true); err != nil || !reflect.DeepEqual(blocksOf(found), []uint64{3, 12, 27}) {
+		t.Fatalf("Scan: found blocks %v (err: %v), expected [3 12 27]", blocksOf(found), err)
+	}
+	if stats, err := in.Sync(ctx); err != nil || stats != (Stats{}) {
+		t.Fatalf("Sync without new blocks: got %+v (err: %v)", stats, err)
+	}
+
+	// Blocks from 25 are replaced by a fork that pays at 26 instead of 27.
+	chain.reorg(25)
+	if err := chain.extend(34, 1, r.MetaAddress(), map[uint64]bool{26: true}); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = in.Sync(ctx); err != nil {
+		t.Fatalf("Sync after the reorganisation: %v", err)
+	}
+	if stats.Reorgs != 1 || stats.Blocks != 7 || stats.Stored != 7 {
+		t.Fatalf("Sync after the reorganisation: got %+v, expected 1 reorganisation and 7 blocks read again", stats)
+	}
+	if err := chain.checkIndex(ix, 1, 31); err != nil {
+		t.Fatalf("after the reorganisation: %v", err)
+	}
+	if found, _, err := ix.Scan("r", p, r, 2, true); err != nil || !reflect.DeepEqual(blocksOf(found), []uint64{26}) {
+		t.Fatalf("Scan after the reorganisation: found blocks %v (err: %v), expected [26]", blocksOf(found), err)
+	}
+
+	// A page larger than the endpoint allows fails with its error.
+	wide := &Ingester{Client: NewClient(server.URL), Index: ix, Contract: "0x0000000000000000000000000000000000005564", PageSize: 8}
+	var rpcErr *RPCError
+	if _, err := wide.Sync(ctx); !errors.As(err, &rpcErr) {
+		t.Fatalf("Sync with pages over the range limit: got %v, expected a JSON-RPC error", err)
+	}
+}
+
+// TestEncodings checks the scheme ID, stealth meta-address and announce
+// calldata of every protocol, and that none uses the ERC's secp256k1 scheme.
+func TestEncodings(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testEncodings(t, p) })
+	}
+}
+
+func testEncodings(t *testing.T, p protocol.Protocol) {
+	id, err := SchemeID(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q, err := Scheme(id); err != nil || protocol.ID(q) != protocol.ID(p) {
+		t.Fatalf("Scheme(%d): got %v (err: %v)", id, q, err)
+	}
+	if id == SchemeSecp256k1 {
+		t.Fatalf("published under the ERC's secp256k1 scheme %d", id)
+	}
+
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := r.MetaAddress()
+	for _, s := range []string{FormatMetaAddress("eth", meta), "0x" + hex.EncodeToString(meta.Bytes())} {
+		parsed, err := ParseMetaAddress(p, s)
+		if err != nil || !reflect.DeepEqual(parsed, meta) {
+			t.Fatalf("ParseMetaAddress(%q): meta address does not round trip (err: %v)", s, err)
+		}
+	}
+	for _, s := range []string{"st:eth:" + hex.EncodeToString(meta.Bytes()), "st::0x" + hex.EncodeToString(meta.Bytes()), FormatMetaAddress("eth", meta) + "00"} {
+		if _, err := ParseMetaAddress(p, s); err == nil {
+			t.Fatalf("ParseMetaAddress(%q): accepted a malformed meta address", s)
+		}
+	}
+
+	ann, err := p.Send(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calldata, err := EncodeAnnounce(p, ann)
+	if err != nil {
+		t.Fatalf("EncodeAnnounce: %v", err)
+	}
+	if len(calldata) < 4 || [4]byte(calldata[:4]) != AnnounceSelector {
+		t.Fatal("EncodeAnnounce: missing the announce selector")
+	}
+	args := calldata[4:]
+	scheme, _ := abiWord(args, 0)
+	var address protocol.Address
+	copy(address[:], args[64-protocol.AddressLength:64])
+	ephemeral, err := abiBytes(args, 2)
+	if err != nil {
+		t.Fatalf("EncodeAnnounce: ephemeral key: %v", err)
+	}
+	metadata, err := abiBytes(args, 3)
+	if err != nil {
+		t.Fatalf("EncodeAnnounce: metadata: %v", err)
+	}
+	if scheme != id || address != ann.Address || !reflect.DeepEqual(ephemeral, ann.Ephemeral) || !reflect.DeepEqual(metadata, []byte{ann.ViewTag}) {
+		t.Fatal("EncodeAnnounce: calldata does not decode to the announcement")
+	}
+}
+
+func blocksOf(entries []indexer.Entry) []uint64 {
+	var blocks []uint64
+	for _, e := range entries {
+		blocks = append(blocks, e.Block)
+	}
+	return blocks
+}
+
+// mockBlock is a block of the mock chain with the logs of the announcer.
+type mockBlock struct {
+	hash indexer.Hash
+	logs []Log
+	// entries are the announcements the valid logs decode to.
+	entries []indexer.Entry
+}
+
+// mockChain serves eth_blockNumber, eth_getBlockByNumber and eth_getLogs
+// over its blocks, rejecting log queries over more than maxRange blocks.
+type mockChain struct {
+	contract string
+	maxRange uint64
+
+	mu     sync.Mutex
+	blocks []mockBlock
+}
+
+func mockHash(kind string, values ...uint64) indexer.Hash {
+	h := sha256.New()
+	h.Write([]byte(kind))
+	for _, v := range values {
+		h.Write(binary.BigEndian.AppendUint64(nil, v))
+	}
+	return indexer.Hash(h.Sum(nil))
+}
+
+// extend appends blocks of fork until the chain has n. Every block but the
+// genesis announces once, paying meta in paid blocks; blocks 5, 6, 8 and 9
+// add a log of an unknown scheme, a log of the ERC's secp256k1 scheme, a
+// removed log and a truncated ephemeral key.
+func (c *mockChain) extend(n, fork uint64, meta protocol.MetaAddress, paid map[uint64]bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for number := uint64(len(c.blocks)); number < n; number++ {
+		b := mockBlock{hash: mockHash("block", fork, number)}
+		if number > 0 {
+			s := schemes[number%uint64(len(schemes))]
+			scheme, p := s.id, s.p
+			var ann protocol.Announcement
+			var err error
+			if paid[number] {
+				scheme, p = 2, protocol.ECPDKSAPBN254()
+				ann, err = p.Send(meta)
+			} else {
+				ann, err = p.RandomAnnouncement()
+			}
+			if err != nil {
+				return err
+			}
+			l := c.log(b.hash, fork, number, 0, scheme, ann)
+			e, err := DecodeLog(l)
+			if err != nil {
+				return err
+			}
+			b.logs, b.entries = append(b.logs, l), append(b.entries, e)
+		}
+		switch number {
+		case 5:
+			ann, err := protocol.DKSAPSecp256k1().RandomAnnouncement()
+			if err != nil {
+				return err
+			}
+			b.logs = append(b.logs, c.log(b.hash, fork, number, 1, 99, ann))
+		case 6:
+			ann, err := protocol.DKSAPSecp256k1().RandomAnnouncement()
+			if err != nil {
+				return err
+			}
+			b.logs = append(b.logs, c.log(b.hash, fork, number, 1, SchemeSecp256k1, ann))
+		case 8:
+			ann, err := protocol.DKSAPSecp256k1().RandomAnnouncement()
+			if err != nil {
+				return err
+			}
+			l := c.log(b.hash, fork, number, 1, 6, ann)
+			l.Removed = true
+			b.logs = append(b.logs, l)
+		case 9:
+			ann, err := protocol.ECPDKSAPBN254().RandomAnnouncement()
+			if err != nil {
+				return err
+			}
+			ann.Ephemeral = ann.Ephemeral[1:]
+			b.logs = append(b.logs, c.log(b.hash, fork, number, 1, 2, ann))
+		}
+		c.blocks = append(c.blocks, b)
+	}
+	return nil
+}
+
+// reorg drops the blocks from number on.
+func (c *mockChain) reorg(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.blocks = c.blocks[:number]
+}
+
+// log encodes ann as an Announcement log of the contract.
+func (c *mockChain) log(blockHash indexer.Hash, fork, number uint64, logIndex uint32, scheme uint64, ann protocol.Announcement) Log {
+	var schemeTopic, addressTopic indexer.Hash
+	binary.BigEndian.PutUint64(schemeTopic[24:], scheme)
+	copy(addressTopic[32-protocol.AddressLength:], ann.Address[:])
+	caller := mockHash("caller", number)
+	clear(caller[:32-protocol.AddressLength])
+
+	ephemeral := abiPadded(ann.Ephemeral)
+	data := make([]byte, 64)
+	binary.BigEndian.PutUint64(data[24:], 64)
+	binary.BigEndian.PutUint64(data[56:], uint64(64+len(ephemeral)))
+	data = append(append(data, ephemeral...), abiPadded([]byte{ann.ViewTag})...)
+
+	return Log{
+		Address:         c.contract,
+		Topics:          []string{indexer.Hash(AnnouncementTopic).String(), schemeTopic.String(), addressTopic.String(), caller.String()},
+		Data:            "0x" + hex.EncodeToString(data),
+		BlockNumber:     encodeQuantity(number),
+		BlockHash:       blockHash.String(),
+		TransactionHash: mockHash("tx", fork, number, uint64(logIndex)).String(),
+		LogIndex:        encodeQuantity(uint64(logIndex)),
+	}
+}
+
+// checkIndex compares the announcements and block hashes stored for blocks
+// from to to with the chain's.
+func (c *mockChain) checkIndex(ix *indexer.Index, from, to uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var expected, stored []indexer.Entry
+	for number := from; number <= to; number++ {
+		expected = append(expected, c.blocks[number].entries...)
+	}
+	if err := ix.Range(from, to, func(e indexer.Entry) error { stored = append(stored, e); return nil }); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(stored, expected) {
+		return fmt.Errorf("index holds %d announcements of blocks %d to %d that differ from the chain's %d", len(stored), from, to, len(expected))
+	}
+	var mismatch error
+	err := ix.BlockHashes(to, func(block uint64, hash indexer.Hash) bool {
+		if block < from {
+			return false
+		}
+		if hash != c.blocks[block].hash {
+			mismatch = fmt.Errorf("stored hash of block %d differs from the chain's", block)
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return mismatch
+}
+
+func (c *mockChain) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var call struct {
+		ID     uint64            `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&call); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, rpcErr := c.handle(call.Method, call.Params)
+	resp := map[string]any{"jsonrpc": "2.0", "id": call.ID}
+	if rpcErr != nil {
+		resp["error"] = rpcErr
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (c *mockChain) handle(method string, params []json.RawMessage) (any, *RPCError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	invalid := &RPCError{Code: -32602, Message: "invalid params"}
+	switch method {
+	case "eth_blockNumber":
+		return encodeQuantity(uint64(len(c.blocks) - 1)), nil
+	case "eth_getBlockByNumber":
+		var number string
+		if len(params) != 2 || json.Unmarshal(params[0], &number) != nil {
+			return nil, invalid
+		}
+		n, err := decodeQuantity(number)
+		if err != nil {
+			return nil, invalid
+		}
+		if n >= uint64(len(c.blocks)) {
+			return nil, nil
+		}
+		return map[string]string{"number": number, "hash": c.blocks[n].hash.String()}, nil
+	case "eth_getLogs":
+		var filter struct {
+			FromBlock string   `json:"fromBlock"`
+			ToBlock   string   `json:"toBlock"`
+			Address   string   `json:"address"`
+			Topics    []string `json:"topics"`
+		}
+		if len(params) != 1 || json.Unmarshal(params[0], &filter) != nil {
+			return nil, invalid
+		}
+		from, err := decodeQuantity(filter.FromBlock)
+		if err != nil {
+			return nil, invalid
+		}
+		to, err := decodeQuantity(filter.ToBlock)
+		if err != nil || to < from {
+			return nil, invalid
+		}
+		if to-from+1 > c.maxRange {
+			return nil, &RPCError{Code: -32005, Message: fmt.Sprintf("block range exceeds %d", c.maxRange)}
+		}
+		logs := []Log{}
+		if filter.Address != c.contract || len(filter.Topics) != 1 || filter.Topics[0] != indexer.Hash(AnnouncementTopic).String() {
+			return logs, nil
+		}
+		for n := from; n <= to && n < uint64(len(c.blocks)); n++ {
+			logs = append(logs, c.blocks[n].logs...)
+		}
+		return logs, nil
+	}
+	return nil, &RPCError{Code: -32601, Message: "method not found"}
+}
@@ -0,0 +1,195 @@
+package erc5564
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sap-go/indexer"
+	"strings"
+)
+
+// DefaultPageSize is the number of blocks requested per eth_getLogs call when
+// the ingester has no page size.
+const DefaultPageSize = 2000
+
+// Ingester copies the Announcement events of an ERC5564Announcer contract
+// into an index. Its progress is stored as an index cursor, so a later Sync
+// continues where the previous one stopped.
+type Ingester struct {
+	Client *Client
+	Index  *indexer.Index
+	// Contract is the 0x prefixed address of the announcer.
+	Contract string
+	// Start is the first block read when nothing was ingested yet.
+	Start uint64
+	// PageSize is the number of blocks per eth_getLogs call.
+	PageSize uint64
+	// Confirmations is the number of most recent blocks left unread.
+	Confirmations uint64
+}
+
+// Stats counts what a Sync read and stored. Skipped includes the
+// announcements of the ERC's secp256k1 scheme, also counted in Secp256k1.
+type Stats struct {
+	Blocks    uint64
+	Logs      int
+	Stored    int
+	Skipped   int
+	Secp256k1 int
+	Reorgs    int
+}
+
+// CursorName returns the name of the index cursor holding the next block to
+// read from contract.
+func CursorName(contract string) string {
+	return "erc5564/" + strings.ToLower(contract)
+}
+
+// Sync reads the blocks after the last ingested one up to the latest block
+// less the confirmations. Before each page it checks that the last ingested
+// block is still on the chain; if it was replaced, the index is rewound to the
+// newest block whose stored hash still matches and reading resumes after it.
+// Logs that are removed, of an unknown scheme or malformed are skipped, as
+// are those of scheme 1 until its Keccak-256 derivation is implemented.
+func (in *Ingester) Sync(ctx context.Context) (Stats, error) {
+	var stats Stats
+	head, err := in.Client.BlockNumber(ctx)
+	if err != nil {
+		return stats, err
+	}
+	if head < in.Confirmations {
+		return stats, nil
+	}
+	target := head - in.Confirmations
+	pageSize := in.PageSize
+	if pageSize == 0 {
+		pageSize = DefaultPageSize
+	}
+	cursor, err := in.Index.Cursor(CursorName(in.Contract))
+	if err != nil {
+		return stats, err
+	}
+	next := max(cursor.Block, in.Start)
+
+	for next <= target {
+		if next > in.Start {
+			rewound, err := in.checkReorg(ctx, next-1)
+			if err != nil {
+				return stats, err
+			}
+			if rewound != next {
+				stats.Reorgs++
+				next = rewound
+				continue
+			}
+		}
+		to := min(next+pageSize-1, target)
+		if to < next {
+			to = target // next+pageSize overflowed
+		}
+		hash, err := in.Client.BlockHash(ctx, to)
+		if err != nil {
+			return stats, err
+		}
+		logs, err := in.Client.Logs(ctx, in.Contract, next, to)
+		if err != nil {
+			return stats, err
+		}
+		// the page is only stored if its last block did not change while it
+		// was read
+		if again, err := in.Client.BlockHash(ctx, to); err != nil {
+			return stats, err
+		} else if again != hash {
+			continue
+		}
+
+		blocks := map[uint64]indexer.Hash{to: hash}
+		var entries []indexer.Entry
+		for _, l := range logs {
+			stats.Logs++
+			e, blockHash, err := in.decode(l)
+			if err != nil || e.Block < next || e.Block > to {
+				if errors.Is(err, ErrSchemeSecp256k1) {
+					stats.Secp256k1++
+				}
+				stats.Skipped++
+				continue
+			}
+			entries = append(entries, e)
+			blocks[e.Block] = blockHash
+		}
+		if err := in.Index.PutBlocks(blocks, entries...); err != nil {
+			return stats, err
+		}
+		if err := in.Index.SetCursor(CursorName(in.Contract), indexer.Position{Block: to + 1}); err != nil {
+			return stats, err
+		}
+		stats.Blocks += to - next + 1
+		stats.Stored += len(entries)
+		next = to + 1
+	}
+	return stats, nil
+}
+
+// decode turns a log of the contract into a valid index entry.
+func (in *Ingester) decode(l Log) (indexer.Entry, indexer.Hash, error) {
+	if l.Removed {
+		return indexer.Entry{}, indexer.Hash{}, errors.New("removed log")
+	}
+	if !strings.EqualFold(l.Address, in.Contract) {
+		return indexer.Entry{}, indexer.Hash{}, fmt.Errorf("log of %s", l.Address)
+	}
+	e, err := DecodeLog(l)
+	if err != nil {
+		return indexer.Entry{}, indexer.Hash{}, err
+	}
+	if _, _, err := e.Decode(); err != nil {
+		return indexer.Entry{}, indexer.Hash{}, err
+	}
+	blockHash, err := decodeHash(l.BlockHash)
+	if err != nil {
+		return indexer.Entry{}, indexer.Hash{}, err
+	}
+	return e, blockHash, nil
+}
+
+// checkReorg compares the stored hash of block with the chain's. If they
+// differ, it rewinds the index after the newest stored block the chain still
+// has, or to the start if there is none, and returns the block to read next.
+func (in *Ingester) checkReorg(ctx context.Context, block uint64) (uint64, error) {
+	var ancestor uint64
+	var found bool
+	var rpcErr error
+	err := in.Index.BlockHashes(block, func(b uint64, stored indexer.Hash) bool {
+		if b < in.Start {
+			return false
+		}
+		hash, err := in.Client.BlockHash(ctx, b)
+		if err != nil {
+			rpcErr = err
+			return false
+		}
+		if hash == stored {
+			ancestor, found = b, true
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if rpcErr != nil {
+		return 0, rpcErr
+	}
+	if found && ancestor == block {
+		return block + 1, nil
+	}
+	next := in.Start
+	if found {
+		next = ancestor + 1
+	}
+	if err := in.Index.Rewind(next); err != nil {
+		return 0, err
+	}
+	return next, nil
+}
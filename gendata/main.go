@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"sap-go/bench"
+	"sap-go/config"
+	"sap-go/dataset"
+	"sap-go/protocol"
+	"time"
+)
+
+func main() {
+	protocolName := flag.String("protocol", "ecpdksap", "protocol to generate announcements for")
+	curveName := flag.String("curve", "bn254", "curve the protocol is instantiated on")
+	n := flag.Int("n", config.RunNumber, "number of announcements")
+	fraction := flag.Float64("fraction", 0.0002, "fraction of announcements addressed to the recipient")
+	seed := flag.Int64("seed", 0, "seed the recipient, announcements and owned positions are generated from (0 picks a random seed)")
+	spendingKey := flag.String("spend", "", "hex spending private key of the recipient (random if empty)")
+	viewingKey := flag.String("view", "", "hex viewing private key of the recipient (random if empty)")
+	out := flag.String("out", "", "dataset file to write (default dataset_<protocol>_<curve>_<n>.sap)")
+	flag.Parse()
+
+	p, err := protocol.Lookup(*protocolName, *curveName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if *n <= 0 || *fraction < 0 || *fraction > 1 {
+		fmt.Println("-n must be positive and -fraction between 0 and 1")
+		os.Exit(2)
+	}
+
+	opts := bench.Options{Announcements: *n, Owned: int(math.Round(*fraction * float64(*n))), Seed: *seed}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+
+	var recipient protocol.Recipient
+	if *spendingKey == "" && *viewingKey == "" {
+		recipient, err = dataset.GenerateRecipient(p, opts.Seed)
+	} else {
+		recipient, err = parseRecipient(p, *spendingKey, *viewingKey)
+	}
+	if err != nil {
+		fmt.Println("Error creating recipient:", err)
+		os.Exit(1)
+	}
+	startTime := time.Now()
+	d, err := dataset.Generate(p, recipient, opts.OwnedPositions(), opts.Seed)
+	if err != nil {
+		fmt.Println("Error generating announcements:", err)
+		os.Exit(1)
+	}
+
+	path := *out
+	if path == "" {
+		path = dataset.FileName(p.Name(), p.Curve(), *n)
+	}
+	if err := dataset.Save(path, d); err != nil {
+		fmt.Println("Error writing dataset:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Generated %d announcements (%d owned, seed %d) for %s in %v\n", len(d.Announcements), d.Owned, d.Seed, protocol.ID(p), time.Since(startTime))
+	fmt.Println("Dataset saved to", path)
+}
+
+func parseRecipient(p protocol.Protocol, spendingKey, viewingKey string) (protocol.Recipient, error) {
+	k, err := hex.DecodeString(spendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending key: %w", err)
+	}
+	v, err := hex.DecodeString(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return p.NewRecipient(k, v)
+}
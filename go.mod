@@ -2,11 +2,21 @@ module sap-go
 
 go 1.21.3
 
+require (
+	github.com/consensys/gnark-crypto v0.12.1
+	go.etcd.io/bbolt v1.3.10
+	golang.org/x/crypto v0.33.0
+	golang.org/x/text v0.22.0
+	google.golang.org/grpc v1.66.2
+	google.golang.org/protobuf v1.34.2
+)
+
 require (
 	github.com/bits-and-blooms/bitset v1.7.0 // indirect
 	github.com/consensys/bavard v0.1.13 // indirect
-	github.com/consensys/gnark-crypto v0.12.1 // indirect
 	github.com/mmcloughlin/addchain v0.4.0 // indirect
-	golang.org/x/sys v0.9.0 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240604185151-ef581f913117 // indirect
 	rsc.io/tmplfunc v0.0.3 // indirect
 )
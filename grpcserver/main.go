@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"sap-go/annfile"
+	"sap-go/rpc"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:9090", "address to listen on; without -tls-cert, clients send viewing keys in the clear, so keep it on loopback")
+	announcements := flag.String("announcements", "", "JSON Lines announcement file to scan")
+	tlsCert := flag.String("tls-cert", "", "PEM certificate file to serve TLS with")
+	tlsKey := flag.String("tls-key", "", "PEM private key file of -tls-cert")
+	flag.Parse()
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fmt.Println("-tls-cert and -tls-key must be given together")
+		os.Exit(2)
+	}
+	var options []grpc.ServerOption
+	if *tlsCert != "" {
+		creds, err := credentials.NewServerTLSFromFile(*tlsCert, *tlsKey)
+		if err != nil {
+			fmt.Println("Error loading TLS certificate:", err)
+			os.Exit(1)
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	server := rpc.NewServer()
+	if *announcements != "" {
+		records, err := annfile.Load(*announcements)
+		if err != nil {
+			fmt.Println("Error reading announcements:", err)
+			os.Exit(1)
+		}
+		if err := server.Add(records); err != nil {
+			fmt.Println("Error loading announcements:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d announcements from %s\n", len(records), *announcements)
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Println("Error listening:", err)
+		os.Exit(1)
+	}
+	grpcServer := grpc.NewServer(options...)
+	rpc.RegisterStealthAddressServer(grpcServer, server)
+	if *tlsCert != "" {
+		fmt.Println("Listening with TLS on", *addr)
+	} else {
+		fmt.Println("Listening without TLS on", *addr)
+	}
+	if err := grpcServer.Serve(listener); err != nil {
+		fmt.Println("Error serving:", err)
+		os.Exit(1)
+	}
+}
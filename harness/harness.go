@@ -0,0 +1,361 @@
+package harness
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"runtime"
+	"sap-go/bench"
+	"sap-go/dataset"
+	"sap-go/protocol"
+	"sap-go/results"
+	"sap-go/scanner"
+	"time"
+)
+
+// Fixture is a recipient and the decoded announcement set it scans.
+// Ephemeral keys are decoded up front so decompression is not part of the
+// measured scan, and a fixture is reused across runs.
+type Fixture struct {
+	Recipient     protocol.Recipient
+	Announcements []protocol.Decoded
+	Owned         int
+}
+
+// GenerateDataset creates a dataset of opts.Announcements announcements,
+// opts.Owned of them addressed to the recipient. The recipient, the
+// announcements and the owned positions are all generated from opts.Seed.
+func GenerateDataset(p protocol.Protocol, opts *bench.Options) (*dataset.Dataset, error) {
+	seeded := *opts
+	if seeded.Seed == 0 {
+		seeded.Seed = time.Now().UnixNano()
+	}
+	recipient, err := dataset.GenerateRecipient(p, seeded.Seed)
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Generate(p, recipient, seeded.OwnedPositions(), seeded.Seed)
+}
+
+// NewFixture loads the dataset named by opts.Dataset, or generates one when
+// no dataset is given, and decodes it for scanning.
+func NewFixture(p protocol.Protocol, opts *bench.Options) (*Fixture, error) {
+	var d *dataset.Dataset
+	var err error
+	if opts.Dataset != "" {
+		d, err = dataset.Load(opts.Dataset)
+	} else {
+		d, err = GenerateDataset(p, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return FixtureFromDataset(p, d)
+}
+
+// FixtureFromDataset restores the recipient of d and decodes its announcements.
+func FixtureFromDataset(p protocol.Protocol, d *dataset.Dataset) (*Fixture, error) {
+	recipient, err := d.Recipient(p)
+	if err != nil {
+		return nil, err
+	}
+	announcements, err := d.Decode(p)
+	if err != nil {
+		return nil, err
+	}
+	return &Fixture{Recipient: recipient, Announcements: announcements, Owned: d.Owned}, nil
+}
+
+// SearchSpeed times one full scan over the fixture.
+func SearchSpeed(p protocol.Protocol, f *Fixture, viewTags bool) (bench.Result, error) {
+	result := bench.Result{Announcements: len(f.Announcements), Owned: f.Owned, ViewTags: viewTags}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	startTime := time.Now()
+	_, stats, err := scanner.Scan(p, f.Recipient, f.Announcements, viewTags)
+	result.Duration = time.Since(startTime)
+	runtime.ReadMemStats(&after)
+	result.Allocs = after.Mallocs - before.Mallocs
+
+	result.Matches = stats.Matches
+	result.ViewTagHits = stats.ViewTagHits
+	return result, err
+}
+
+// StageBreakdown runs the view tag scan and attributes its time to the
+// individual stages, and the time of the protocol calls to their primitives.
+func StageBreakdown(p protocol.Protocol, f *Fixture) (bench.Breakdown, error) {
+	recipient, announcements := f.Recipient, f.Announcements
+	breakdown := bench.Breakdown{Protocol: p.Name(), Curve: p.Curve(), Announcements: len(announcements)}
+	var viewTagTimer, deriveTimer protocol.Timer
+	viewTagProtocol := protocol.Instrument(p, &viewTagTimer)
+	deriveProtocol := protocol.Instrument(p, &deriveTimer)
+
+	startTime := time.Now()
+
+	for i := range announcements {
+		ann := &announcements[i]
+		start := time.Now()
+		viewTag, err := viewTagProtocol.ViewTag(recipient, ann.Ephemeral)
+		breakdown.Stop(bench.StageViewTag, start)
+		if err != nil {
+			return breakdown, err
+		}
+		if viewTag != ann.ViewTag {
+			continue
+		}
+
+		start = time.Now()
+		stealthPublicKey, err := deriveProtocol.StealthPublicKey(recipient, ann.Ephemeral)
+		breakdown.Stop(bench.StageDerive, start)
+		if err != nil {
+			return breakdown, err
+		}
+
+		start = time.Now()
+		address := protocol.AddressFromPublicKey(stealthPublicKey)
+		breakdown.Stop(bench.StageHash, start)
+
+		start = time.Now()
+		_ = address.String()
+		breakdown.Stop(bench.StageFormat, start)
+	}
+
+	breakdown.Total = time.Since(startTime)
+	for _, primitive := range protocol.Primitives {
+		if calls := viewTagTimer.Calls[primitive]; calls > 0 {
+			breakdown.AddPrimitive(bench.StageViewTag, primitive.String(), calls, viewTagTimer.Durations[primitive])
+		}
+		if calls := deriveTimer.Calls[primitive]; calls > 0 {
+			breakdown.AddPrimitive(bench.StageDerive, primitive.String(), calls, deriveTimer.Durations[primitive])
+		}
+	}
+	return breakdown, nil
+}
+
+// Experiment runs runs view tag scans over f and returns one record per run.
+func Experiment(p protocol.Protocol, f *Fixture, runs int) ([]results.Record, error) {
+	records := make([]results.Record, 0, runs)
+	for i := 0; i < runs; i++ {
+		result, err := SearchSpeed(p, f, true)
+		if err != nil {
+			return records, err
+		}
+		result.Print("Search using view tag")
+		records = append(records, results.Record{
+			Protocol:   p.Name(),
+			Curve:      p.Curve(),
+			Run:        i + 1,
+			DurationMs: float64(result.Duration.Microseconds()) / 1000,
+			PublicKeys: len(f.Announcements),
+
+			AllocsPerAnnouncement: result.AllocsPerAnnouncement(),
+		})
+	}
+	return records, nil
+}
+
+// Profile runs measure with the profiles selected in opts and reports the
+// files written. label distinguishes measurements of the same configuration.
+func Profile(p protocol.Protocol, f *Fixture, opts *bench.Options, label string, measure func() error) error {
+	profiler, err := bench.StartProfiling(opts, p.Name(), p.Curve(), len(f.Announcements), label)
+	if err != nil {
+		return err
+	}
+	measureErr := measure()
+	files, err := profiler.Stop()
+	for _, file := range files {
+		fmt.Println("Profile saved to", file)
+	}
+	return errors.Join(measureErr, err)
+}
+
+// RunExperiment runs opts.Runs scans over f and saves the results as CSV in opts.Out.
+func RunExperiment(p protocol.Protocol, f *Fixture, opts *bench.Options) error {
+	var records []results.Record
+	err := Profile(p, f, opts, "experiment", func() (err error) {
+		records, err = Experiment(p, f, opts.Runs)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Save results to CSV file
+	fileName := opts.Path(results.FileName(p.Name(), p.Curve(), len(f.Announcements)))
+	if err := results.Save(fileName, records); err != nil {
+		return fmt.Errorf("error writing CSV file: %w", err)
+	}
+	fmt.Println("Experiment results saved to", fileName)
+	return nil
+}
+
+// RunStageBreakdown prints the stage breakdown and saves it as CSV in opts.Out.
+func RunStageBreakdown(p protocol.Protocol, f *Fixture, opts *bench.Options) error {
+	var breakdown bench.Breakdown
+	err := Profile(p, f, opts, "breakdown", func() (err error) {
+		breakdown, err = StageBreakdown(p, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	breakdown.Print()
+
+	fileName := opts.Path(bench.BreakdownFileName(p.Name(), p.Curve(), breakdown.Announcements))
+	if err := bench.SaveBreakdowns(fileName, []bench.Breakdown{breakdown}); err != nil {
+		return fmt.Errorf("error writing CSV file: %w", err)
+	}
+	fmt.Println("Stage breakdown saved to", fileName)
+	return nil
+}
+
+// demo sends one payment to a fresh recipient and prints the announcement.
+func demo(p protocol.Protocol) error {
+	recipient, err := p.GenerateRecipient()
+	if err != nil {
+		return err
+	}
+	ann, err := p.Send(recipient.MetaAddress())
+	if err != nil {
+		return err
+	}
+	fmt.Println("Formatted Stealth Address:", ann.Address)
+	fmt.Println("View Tag:", ann.ViewTag)
+	return nil
+}
+
+// Main is the command line entry point shared by the protocol programs. It
+// prints a sample announcement and then runs the searches, the experiment or
+// the stage breakdown depending on the flags.
+func Main(p protocol.Protocol) {
+	opts := bench.Flags()
+	experiment := flag.Bool("experiment", false, "run the view tag experiment and save the results as CSV")
+	breakdown := flag.Bool("breakdown", false, "report the per-stage cost of the view tag scan and save it as CSV")
+	flag.Parse()
+
+	if err := demo(p); err != nil {
+		fmt.Println("Error computing stealth address:", err)
+		return
+	}
+
+	fixture, err := NewFixture(p, opts)
+	if err != nil {
+		fmt.Println("Error preparing announcements:", err)
+		return
+	}
+
+	if !*experiment && !*breakdown {
+		err := Profile(p, fixture, opts, "search", func() error {
+			for _, viewTags := range []bool{false, true} {
+				label := "Search"
+				if viewTags {
+					label = "Search using view tag"
+				}
+				result, err := SearchSpeed(p, fixture, viewTags)
+				if err != nil {
+					return err
+				}
+				result.Print(label)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Println("Error scanning announcements:", err)
+			return
+		}
+	}
+	if *experiment {
+		if err := RunExperiment(p, fixture, opts); err != nil {
+			fmt.Println("Error running experiment:", err)
+		}
+	}
+	if *breakdown {
+		if err := RunStageBreakdown(p, fixture, opts); err != nil {
+			fmt.Println("Error running stage breakdown:", err)
+		}
+	}
+}
+
+// Procs returns the GOMAXPROCS settings of a multi-core experiment: powers of
+// two below maxProcs followed by maxProcs itself.
+func Procs(maxProcs int) []int {
+	var procs []int
+	for p := 1; p < maxProcs; p *= 2 {
+		procs = append(procs, p)
+	}
+	return append(procs, max(maxProcs, 1))
+}
+
+// Multicore scans f runs times at each GOMAXPROCS setting in procs, with one
+// scanning goroutine per proc, and summarizes speedup and parallel efficiency.
+func Multicore(p protocol.Protocol, f *Fixture, procs []int, runs int) ([]results.CoreSummary, error) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	durations := make(map[int][]float64, len(procs))
+	for _, n := range procs {
+		runtime.GOMAXPROCS(n)
+		for i := 0; i < runs; i++ {
+			startTime := time.Now()
+			_, stats, err := scanner.ScanParallel(p, f.Recipient, scanner.Slice(f.Announcements), n, true)
+			duration := time.Since(startTime)
+			if err != nil {
+				return nil, err
+			}
+			if stats.Matches != f.Owned {
+				return nil, fmt.Errorf("found %d of %d owned announcements", stats.Matches, f.Owned)
+			}
+			durations[n] = append(durations[n], float64(duration.Microseconds())/1000)
+		}
+		fmt.Printf("%s: GOMAXPROCS=%d, %.2f ms\n", protocol.ID(p), n, results.Mean(durations[n]))
+	}
+	key := results.Key{Protocol: p.Name(), Curve: p.Curve(), PublicKeys: len(f.Announcements)}
+	return results.CoreScaling(key, durations)
+}
+
+// Batch scans f for each number of recipients in sizes, runs times: once with
+// scanner.ScanBatch and once with a scanner.ScanParallel per recipient. The
+// fixture's recipient is the first one and the others own nothing.
+func Batch(p protocol.Protocol, f *Fixture, sizes []int, runs int) ([]results.BatchSummary, error) {
+	src := scanner.Slice(f.Announcements)
+	workers := runtime.GOMAXPROCS(0)
+	recipients := []protocol.Recipient{f.Recipient}
+	key := results.Key{Protocol: p.Name(), Curve: p.Curve(), PublicKeys: len(f.Announcements)}
+
+	var summaries []results.BatchSummary
+	for _, n := range sizes {
+		for len(recipients) < n {
+			r, err := p.GenerateRecipient()
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, r)
+		}
+		var batch, separate []float64
+		for i := 0; i < runs; i++ {
+			startTime := time.Now()
+			matches, _, err := scanner.ScanBatch(p, recipients[:n], src, workers, true)
+			duration := time.Since(startTime)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches[0]) != f.Owned {
+				return nil, fmt.Errorf("batch of %d: found %d of %d owned announcements", n, len(matches[0]), f.Owned)
+			}
+			batch = append(batch, float64(duration.Microseconds())/1000)
+
+			startTime = time.Now()
+			for _, r := range recipients[:n] {
+				if _, _, err := scanner.ScanParallel(p, r, src, workers, true); err != nil {
+					return nil, err
+				}
+			}
+			separate = append(separate, float64(time.Since(startTime).Microseconds())/1000)
+		}
+		s := results.BatchScaling(key, n, batch, separate)
+		fmt.Printf("%s: %d recipients, batch %.2f ms, separate %.2f ms\n", protocol.ID(p), n, s.Batch, s.Separate)
+		summaries = append(summaries, s)
+	}
+	return summaries, nil
+}
@@ -0,0 +1,84 @@
+package hdkey
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// The key derivation of EIP-2333 with the BLS12-381 group order replaced by
+// the scalar field modulus of the target curve. With the BLS12-381 order it
+// reproduces the EIP-2333 test vectors.
+
+// MinSeedSize is the smallest seed MasterKey accepts.
+const MinSeedSize = 32
+
+// lamportChunks is the number of 32-byte chunks of each Lamport key.
+const lamportChunks = 255
+
+// ErrSeed is returned for seeds shorter than MinSeedSize.
+var ErrSeed = errors.New("seed must be at least 32 bytes")
+
+// MasterKey derives the root secret key in [1, r) from seed.
+func MasterKey(seed []byte, r *big.Int) (*big.Int, error) {
+	if len(seed) < MinSeedSize {
+		return nil, ErrSeed
+	}
+	return hkdfModR(seed, r), nil
+}
+
+// ChildKey derives the child secret key at index from its parent.
+func ChildKey(parent *big.Int, index uint32, r *big.Int) *big.Int {
+	return hkdfModR(parentToLamportPK(parent, index), r)
+}
+
+// hkdfModR stretches ikm into a non-zero scalar modulo r, retrying with a
+// rehashed salt in the negligible case of zero.
+func hkdfModR(ikm []byte, r *big.Int) *big.Int {
+	// L = ceil(3 * ceil(log2(r)) / 16) bytes leave the result statistically
+	// close to uniform after the reduction.
+	l := (3*r.BitLen() + 15) / 16
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	ikm = append(append([]byte{}, ikm...), 0)
+	info := []byte{byte(l >> 8), byte(l)}
+	sk := new(big.Int)
+	for sk.Sign() == 0 {
+		hashedSalt := sha256.Sum256(salt)
+		salt = hashedSalt[:]
+		okm := make([]byte, l)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, ikm, salt, info), okm); err != nil {
+			panic(err) // l is far below the HKDF output limit
+		}
+		sk.SetBytes(okm).Mod(sk, r)
+	}
+	return sk
+}
+
+// parentToLamportPK compresses the Lamport public key built from the parent
+// key and its bitwise complement.
+func parentToLamportPK(parent *big.Int, index uint32) []byte {
+	salt := binary.BigEndian.AppendUint32(nil, index)
+	ikm := parent.FillBytes(make([]byte, 32))
+	notIKM := make([]byte, len(ikm))
+	for i, b := range ikm {
+		notIKM[i] = ^b
+	}
+
+	lamportPK := make([]byte, 0, 2*lamportChunks*sha256.Size)
+	for _, key := range [][]byte{ikm, notIKM} {
+		okm := make([]byte, lamportChunks*32)
+		if _, err := io.ReadFull(hkdf.New(sha256.New, key, salt, nil), okm); err != nil {
+			panic(err)
+		}
+		for i := 0; i < lamportChunks; i++ {
+			chunk := sha256.Sum256(okm[32*i : 32*(i+1)])
+			lamportPK = append(lamportPK, chunk[:]...)
+		}
+	}
+	compressed := sha256.Sum256(lamportPK)
+	return compressed[:]
+}
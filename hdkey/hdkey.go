@@ -0,0 +1,145 @@
+package hdkey
+
+import (
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"sap-go/codec"
+	"sap-go/protocol"
+	"strings"
+
+	bls12377fr "github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+	bn254fr "github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	secp256k1fr "github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Purpose is the first index of every stealth address key path, after ERC-5564.
+const Purpose = 5564
+
+// scalarSize is the encoded size of a private key on every supported curve.
+const scalarSize = 32
+
+// Role selects the spending or the viewing key of an account.
+type Role uint32
+
+const (
+	Spending Role = 0
+	Viewing  Role = 1
+)
+
+// schemes numbers the protocols in key paths, so the same seed gives
+// unrelated keys to different protocols on one curve.
+var schemes = map[string]uint32{
+	"dksap":              0,
+	"ecpdksap":           1,
+	"ecpdksap-keychange": 2,
+	"ecpsksap":           3,
+}
+
+// Modulus returns the scalar field modulus of the named curve.
+func Modulus(curve string) (*big.Int, error) {
+	switch curve {
+	case "bn254":
+		return bn254fr.Modulus(), nil
+	case "bls12-377":
+		return bls12377fr.Modulus(), nil
+	case "secp256k1":
+		return secp256k1fr.Modulus(), nil
+	}
+	return nil, fmt.Errorf("%w: %q", codec.ErrCurve, curve)
+}
+
+// Path returns m/5564/<curve>/<scheme>/<account>/<role> for p, where curve is
+// the codec curve tag and scheme numbers the protocol.
+func Path(p protocol.Protocol, account uint32, role Role) ([]uint32, error) {
+	curve, err := codec.ParseCurve(p.Curve())
+	if err != nil {
+		return nil, err
+	}
+	scheme, ok := schemes[p.Name()]
+	if !ok {
+		return nil, fmt.Errorf("%w: no key path for %s", protocol.ErrUnknownProtocol, p.Name())
+	}
+	return []uint32{Purpose, uint32(curve), scheme, account, uint32(role)}, nil
+}
+
+// FormatPath renders a path as m/5564/2/1/0/0.
+func FormatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, index := range path {
+		fmt.Fprintf(&b, "/%d", index)
+	}
+	return b.String()
+}
+
+// DeriveKey walks path from the master key of seed.
+func DeriveKey(seed []byte, path []uint32, r *big.Int) (*big.Int, error) {
+	sk, err := MasterKey(seed, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range path {
+		sk = ChildKey(sk, index, r)
+	}
+	return sk, nil
+}
+
+// Keys derives the encoded spending and viewing private keys of an account.
+func Keys(p protocol.Protocol, seed []byte, account uint32) (spendingKey, viewingKey []byte, err error) {
+	var keys [2][]byte
+	for i, role := range []Role{Spending, Viewing} {
+		path, err := Path(p, account, role)
+		if err != nil {
+			return nil, nil, err
+		}
+		if keys[i], err = key(p, seed, path); err != nil {
+			return nil, nil, err
+		}
+	}
+	return keys[0], keys[1], nil
+}
+
+// EpochViewingKey derives the viewing private key an account rotates to at
+// epoch, the child m/5564/<curve>/<scheme>/<account>/1/<epoch> of its viewing
+// key.
+func EpochViewingKey(p protocol.Protocol, seed []byte, account, epoch uint32) ([]byte, error) {
+	path, err := Path(p, account, Viewing)
+	if err != nil {
+		return nil, err
+	}
+	return key(p, seed, append(path, epoch))
+}
+
+// key derives the encoded private key of p at path.
+func key(p protocol.Protocol, seed []byte, path []uint32) ([]byte, error) {
+	r, err := Modulus(p.Curve())
+	if err != nil {
+		return nil, err
+	}
+	sk, err := DeriveKey(seed, path, r)
+	if err != nil {
+		return nil, err
+	}
+	return sk.FillBytes(make([]byte, scalarSize)), nil
+}
+
+// Recipient restores the recipient of an account from seed.
+func Recipient(p protocol.Protocol, seed []byte, account uint32) (protocol.Recipient, error) {
+	spendingKey, viewingKey, err := Keys(p, seed, account)
+	if err != nil {
+		return nil, err
+	}
+	return p.NewRecipient(spendingKey, viewingKey)
+}
+
+// SeedFromMnemonic stretches a BIP-39 mnemonic and optional passphrase into a
+// 64-byte seed. Both are NFKD normalized; the mnemonic's checksum is not
+// verified here.
+func SeedFromMnemonic(mnemonic, passphrase string) []byte {
+	password := norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), 2048, 64, sha512.New)
+}
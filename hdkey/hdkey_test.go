@@ -0,0 +1,137 @@
+package hdkey
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"sap-go/protocol"
+	"testing"
+
+	bls12381fr "github.com/consensys/gnark-crypto/ecc/bls12-381/fr"
+)
+
+// eip2333Vectors are the test cases of EIP-2333, over the BLS12-381 group order.
+var eip2333Vectors = []struct {
+	seed   string
+	master string
+	index  uint32
+	child  string
+}{
+	{
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		"6083874454709270928345386274498605044986640685124978867557563392430687146096",
+		0,
+		"20397789859736650942317412262472558107875392172444076792671091975210932703118",
+	},
+	{
+		"3141592653589793238462643383279502884197169399375105820974944592",
+		"29757020647961307431480504535336562678282505419141012933316116377660817309383",
+		3141592653,
+		"25457201688850691947727629385191704516744796114925897962676248250929345014287",
+	},
+	{
+		"0099ff991111002299dd7744ee3355bbdd8844115566cc55663355668888cc00",
+		"27580842291869792442942448775674722299803720648445448686099262467207037398656",
+		4294967295,
+		"29358610794459428860402234341874281240803786294062035874021252734817515685787",
+	},
+	{
+		"d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3",
+		"19022158461524446591288038168518313374041767046816487870552872741050760015818",
+		42,
+		"31372231650479070279774297061823572166496564838472787488249775572789064611981",
+	},
+}
+
+// The BIP-39 test mnemonic with passphrase "TREZOR" and its seed, which is
+// also the seed of the first EIP-2333 test case.
+const (
+	vectorMnemonic   = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	vectorPassphrase = "TREZOR"
+	vectorSeed       = "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"
+)
+
+// keyVectors are the spending and viewing keys of account 0 derived from
+// vectorSeed, pinned so the paths and the per-curve reduction stay stable.
+var keyVectors = map[string][2]string{
+	"dksap/secp256k1":          {"43d810c555f67894b306dd6240e70c7b8e49b30d5d837b76b52d8a25e32e343f", "ab625dd0c6be450c0d750c0aee096949f9f41c4f2c2a562a72039849ba15d559"},
+	"ecpdksap/bn254":           {"23c06b6910c33774adbd1e7c8c36babba8e15b9b427341644e78966b5ce4534b", "2d4a4486275c83e91486d6fea4cdec3bae7466381b230d98b43d4239fe1472c9"},
+	"ecpdksap/bls12-377":       {"04d3f61e9bb3963860beffb1b69cadc1cc18c320038f2f6402741359591c33a4", "0ad3443ef27c9d051f1571fd9fa1a8f866c2907d5fa3c3c16fcf13fa42d64a62"},
+	"ecpdksap-keychange/bn254": {"1f170689d25817a61fb15a5c05c893a4abebb448177e2572ea80ad2e4d0ddc50", "11bfc4973d6b0fc3baa0b21eb172c46947c93d43a6492269649ba439a30d8ad1"},
+	"ecpsksap/bn254":           {"2f2dad3d9541338d7ebf459d5bcbf22e91b516135e1b3dc198b26764d4b00850", "0ee8e7ff76c0aa98c1cb8b5122ccd137aa2ca534a36e2e0b70c98dd8a6e49fff"},
+}
+
+// TestVectors checks the EIP-2333 and BIP-39 test vectors and the pinned keys
+// of every protocol, that epoch viewing keys are distinct and that derived
+// keys restore a working recipient.
+func TestVectors(t *testing.T) {
+	r := bls12381fr.Modulus()
+	for i, v := range eip2333Vectors {
+		seed, _ := hex.DecodeString(v.seed)
+		master, err := MasterKey(seed, r)
+		if err != nil {
+			t.Fatalf("EIP-2333 case %d: %v", i, err)
+		}
+		if master.String() != v.master {
+			t.Fatalf("EIP-2333 case %d: master key %v, expected %s", i, master, v.master)
+		}
+		if child := ChildKey(master, v.index, r); child.String() != v.child {
+			t.Fatalf("EIP-2333 case %d: child key %v, expected %s", i, child, v.child)
+		}
+	}
+	if _, err := MasterKey(make([]byte, MinSeedSize-1), r); !errors.Is(err, ErrSeed) {
+		t.Fatalf("MasterKey: short seed: got %v, expected %v", err, ErrSeed)
+	}
+
+	seed := SeedFromMnemonic(vectorMnemonic, vectorPassphrase)
+	if hex.EncodeToString(seed) != vectorSeed {
+		t.Fatalf("SeedFromMnemonic: got %x, expected %s", seed, vectorSeed)
+	}
+
+	for _, p := range protocol.All() {
+		want, ok := keyVectors[protocol.ID(p)]
+		if !ok {
+			t.Fatalf("%s: no key vector", protocol.ID(p))
+		}
+		spendingKey, viewingKey, err := Keys(p, seed, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", protocol.ID(p), err)
+		}
+		if hex.EncodeToString(spendingKey) != want[0] || hex.EncodeToString(viewingKey) != want[1] {
+			t.Fatalf("%s: account 0 keys %x %x differ from the vector", protocol.ID(p), spendingKey, viewingKey)
+		}
+		other, _, err := Keys(p, seed, 1)
+		if err != nil {
+			t.Fatalf("%s: %v", protocol.ID(p), err)
+		}
+		if bytes.Equal(other, spendingKey) {
+			t.Fatalf("%s: accounts 0 and 1 share a spending key", protocol.ID(p))
+		}
+		epoch1, err := EpochViewingKey(p, seed, 0, 1)
+		if err != nil {
+			t.Fatalf("%s: EpochViewingKey: %v", protocol.ID(p), err)
+		}
+		epoch2, err := EpochViewingKey(p, seed, 0, 2)
+		if err != nil {
+			t.Fatalf("%s: EpochViewingKey: %v", protocol.ID(p), err)
+		}
+		if bytes.Equal(epoch1, viewingKey) || bytes.Equal(epoch1, epoch2) {
+			t.Fatalf("%s: epoch viewing keys repeat", protocol.ID(p))
+		}
+		recipient, err := Recipient(p, seed, 0)
+		if err != nil {
+			t.Fatalf("%s: Recipient: %v", protocol.ID(p), err)
+		}
+		ann, err := p.Send(recipient.MetaAddress())
+		if err != nil {
+			t.Fatalf("%s: Send: %v", protocol.ID(p), err)
+		}
+		decoded, err := protocol.Decode(p, ann)
+		if err != nil {
+			t.Fatalf("%s: %v", protocol.ID(p), err)
+		}
+		if ok, err := p.Check(recipient, decoded); err != nil || !ok {
+			t.Fatalf("%s: Check: announcement for the derived recipient not matched (err: %v)", protocol.ID(p), err)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sap-go/annfile"
+	"sap-go/protocol"
+	"sap-go/scanner"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// scanChunk is the number of announcements Scan checks before it stores the
+// cursor, bounding the work repeated after an interruption.
+const scanChunk = 4096
+
+var (
+	announcementsBucket = []byte("announcements")
+	cursorsBucket       = []byte("cursors")
+	blocksBucket        = []byte("blocks")
+)
+
+// ErrFormat is returned when a stored entry cannot be decoded.
+var ErrFormat = errors.New("invalid index entry")
+
+// Hash is a 32-byte transaction or block hash.
+type Hash [32]byte
+
+// String returns the 0x prefixed hex representation of the hash.
+func (h Hash) String() string {
+	return "0x" + hex.EncodeToString(h[:])
+}
+
+// Position orders announcements by block, then by log index in the block.
+type Position struct {
+	Block    uint64
+	LogIndex uint32
+}
+
+// Next returns the position right after pos.
+func (pos Position) Next() Position {
+	if pos.LogIndex == ^uint32(0) {
+		return Position{Block: pos.Block + 1}
+	}
+	return Position{Block: pos.Block, LogIndex: pos.LogIndex + 1}
+}
+
+// key encodes pos so that byte order is position order.
+func (pos Position) key() []byte {
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint64(b, pos.Block)
+	binary.BigEndian.PutUint32(b[8:], pos.LogIndex)
+	return b
+}
+
+func positionFromKey(b []byte) (Position, error) {
+	if len(b) != 12 {
+		return Position{}, fmt.Errorf("%w: key of %d bytes", ErrFormat, len(b))
+	}
+	return Position{Block: binary.BigEndian.Uint64(b), LogIndex: binary.BigEndian.Uint32(b[8:])}, nil
+}
+
+// Entry is an announcement together with where it was published.
+type Entry struct {
+	Position
+	TxHash Hash
+	annfile.Record
+}
+
+// Index stores announcements in a bbolt database ordered by position, the
+// cursors of the scanners reading them and the hashes of the blocks they were
+// read from.
+type Index struct {
+	db *bolt.DB
+}
+
+// Open opens or creates the index database at path.
+func Open(path string) (*Index, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{announcementsBucket, cursorsBucket, blocksBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the database.
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Put stores entries in one transaction, replacing any entry at the same
+// position. Entries whose ephemeral key does not decode are rejected before
+// anything is stored, so a scan never stops at one.
+func (ix *Index) Put(entries ...Entry) error {
+	return ix.PutBlocks(nil, entries...)
+}
+
+// PutBlocks is Put that also records the hashes of the blocks the entries
+// were read from, in the same transaction.
+func (ix *Index) PutBlocks(blocks map[uint64]Hash, entries ...Entry) error {
+	for _, e := range entries {
+		if _, _, err := e.Decode(); err != nil {
+			return fmt.Errorf("block %d log %d: %w", e.Block, e.LogIndex, err)
+		}
+	}
+	return ix.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(announcementsBucket)
+		for _, e := range entries {
+			if err := b.Put(e.key(), encodeEntry(e)); err != nil {
+				return err
+			}
+		}
+		hashes := tx.Bucket(blocksBucket)
+		for block, hash := range blocks {
+			if err := hashes.Put(Position{Block: block}.key()[:8], bytes.Clone(hash[:])); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BlockHashes calls fn for the recorded block hashes at or below block,
+// highest first, until fn returns false.
+func (ix *Index) BlockHashes(block uint64, fn func(block uint64, hash Hash) bool) error {
+	return ix.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(blocksBucket).Cursor()
+		seek := Position{Block: block}.key()[:8]
+		k, v := c.Seek(seek)
+		if k == nil || bytes.Compare(k, seek) > 0 {
+			k, v = c.Prev()
+		}
+		for ; k != nil; k, v = c.Prev() {
+			if len(k) != 8 || len(v) != len(Hash{}) {
+				return fmt.Errorf("%w: block hash record", ErrFormat)
+			}
+			var hash Hash
+			copy(hash[:], v)
+			if !fn(binary.BigEndian.Uint64(k), hash) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// Rewind removes the entries and block hashes of block and every later
+// block, after a chain reorganisation replaced them. Cursors past the
+// removed blocks are moved back to block, so scanners read the replacement
+// entries.
+func (ix *Index) Rewind(block uint64) error {
+	start := Position{Block: block}.key()
+	return ix.db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range []struct {
+			name []byte
+			seek []byte
+		}{{announcementsBucket, start}, {blocksBucket, start[:8]}} {
+			c := tx.Bucket(bucket.name).Cursor()
+			for k, _ := c.Seek(bucket.seek); k != nil; k, _ = c.Seek(bucket.seek) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		cursors := tx.Bucket(cursorsBucket)
+		var moved [][]byte
+		err := cursors.ForEach(func(k, v []byte) error {
+			if bytes.Compare(v, start) > 0 {
+				moved = append(moved, bytes.Clone(k))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range moved {
+			if err := cursors.Put(k, start); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Len returns the number of stored announcements.
+func (ix *Index) Len() (int, error) {
+	var n int
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(announcementsBucket).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// Latest returns the position of the last stored announcement, or false if
+// the index is empty.
+func (ix *Index) Latest() (Position, bool, error) {
+	var pos Position
+	var ok bool
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		k, _ := tx.Bucket(announcementsBucket).Cursor().Last()
+		if k == nil {
+			return nil
+		}
+		var err error
+		pos, err = positionFromKey(k)
+		ok = err == nil
+		return err
+	})
+	return pos, ok, err
+}
+
+// From calls fn for the announcements at or after pos in order, stopping
+// after limit of them when limit is positive or when fn returns an error.
+func (ix *Index) From(pos Position, limit int, fn func(Entry) error) error {
+	return ix.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(announcementsBucket).Cursor()
+		n := 0
+		for k, v := c.Seek(pos.key()); k != nil && (limit <= 0 || n < limit); k, v = c.Next() {
+			e, err := decodeEntry(k, v)
+			if err != nil {
+				return err
+			}
+			if err := fn(e); err != nil {
+				return err
+			}
+			n++
+		}
+		return nil
+	})
+}
+
+// errStop ends an iteration early without reporting an error.
+var errStop = errors.New("stop")
+
+// Range calls fn for the announcements of blocks from to to, inclusive, in order.
+func (ix *Index) Range(from, to uint64, fn func(Entry) error) error {
+	err := ix.From(Position{Block: from}, 0, func(e Entry) error {
+		if e.Block > to {
+			return errStop
+		}
+		return fn(e)
+	})
+	if errors.Is(err, errStop) {
+		return nil
+	}
+	return err
+}
+
+// Cursor returns the position the scanner called name resumes from; a new
+// scanner starts at the beginning.
+func (ix *Index) Cursor(name string) (Position, error) {
+	var pos Position
+	err := ix.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(cursorsBucket).Get([]byte(name))
+		if v == nil {
+			return nil
+		}
+		var err error
+		pos, err = positionFromKey(v)
+		return err
+	})
+	return pos, err
+}
+
+// SetCursor stores the position the scanner called name resumes from.
+func (ix *Index) SetCursor(name string, pos Position) error {
+	return ix.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(cursorsBucket).Put([]byte(name), pos.key())
+	})
+}
+
+// entrySource decodes the ephemeral keys of a chunk of entries of one protocol.
+type entrySource struct {
+	p       protocol.Protocol
+	entries []Entry
+}
+
+func (s entrySource) Len() int { return len(s.entries) }
+
+func (s entrySource) Decoded(i int) (protocol.Decoded, error) {
+	return protocol.Decode(s.p, s.entries[i].Announcement())
+}
+
+// Scan scans the announcements of p stored after the cursor called name for
+// r, returning those addressed to r. The cursor is advanced after every
+// chunk, so an interrupted scan resumes close to where it stopped and a
+// later scan only reads announcements stored since.
+func (ix *Index) Scan(name string, p protocol.Protocol, r protocol.Recipient, workers int, viewTags bool) ([]Entry, scanner.Stats, error) {
+	var matches []Entry
+	var stats scanner.Stats
+	pos, err := ix.Cursor(name)
+	if err != nil {
+		return nil, stats, err
+	}
+	for {
+		var chunk []Entry
+		var last *Position
+		err := ix.From(pos, scanChunk, func(e Entry) error {
+			last = &e.Position
+			if e.Protocol == p.Name() && e.Curve == p.Curve() {
+				chunk = append(chunk, e)
+			}
+			return nil
+		})
+		if err != nil {
+			return matches, stats, err
+		}
+		if last == nil {
+			return matches, stats, nil
+		}
+		indices, chunkStats, err := scanner.ScanParallel(p, r, entrySource{p, chunk}, workers, viewTags)
+		stats.Scanned += chunkStats.Scanned
+		stats.ViewTagHits += chunkStats.ViewTagHits
+		stats.Matches += chunkStats.Matches
+		if err != nil {
+			return matches, stats, err
+		}
+		for _, i := range indices {
+			matches = append(matches, chunk[i])
+		}
+		pos = last.Next()
+		if err := ix.SetCursor(name, pos); err != nil {
+			return matches, stats, err
+		}
+	}
+}
+
+// encodeEntry encodes everything but the position, which is the key:
+//
+//	tx hash [32], view tag u8, address [20]
+//	protocol, curve: u8 length + bytes each
+//	ephemeral key: u16 length + bytes
+func encodeEntry(e Entry) []byte {
+	b := make([]byte, 0, 32+1+protocol.AddressLength+2+len(e.Protocol)+len(e.Curve)+2+len(e.Ephemeral))
+	b = append(b, e.TxHash[:]...)
+	b = append(b, e.ViewTag)
+	b = append(b, e.Address[:]...)
+	b = append(b, byte(len(e.Protocol)))
+	b = append(b, e.Protocol...)
+	b = append(b, byte(len(e.Curve)))
+	b = append(b, e.Curve...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(e.Ephemeral)))
+	return append(b, e.Ephemeral...)
+}
+
+func decodeEntry(k, v []byte) (Entry, error) {
+	var e Entry
+	var err error
+	if e.Position, err = positionFromKey(k); err != nil {
+		return Entry{}, err
+	}
+	fixed := 32 + 1 + protocol.AddressLength
+	if len(v) < fixed {
+		return Entry{}, fmt.Errorf("%w: truncated", ErrFormat)
+	}
+	copy(e.TxHash[:], v)
+	e.ViewTag = v[32]
+	copy(e.Address[:], v[33:fixed])
+	v = v[fixed:]
+
+	for _, s := range []*string{&e.Protocol, &e.Curve} {
+		if len(v) < 1 || len(v) < 1+int(v[0]) {
+			return Entry{}, fmt.Errorf("%w: truncated", ErrFormat)
+		}
+		*s, v = string(v[1:1+int(v[0])]), v[1+int(v[0]):]
+	}
+	if len(v) < 2 || len(v) != 2+int(binary.BigEndian.Uint16(v)) {
+		return Entry{}, fmt.Errorf("%w: malformed ephemeral key", ErrFormat)
+	}
+	e.Ephemeral = bytes.Clone(v[2:])
+	return e, nil
+}
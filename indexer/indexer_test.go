@@ -0,0 +1,143 @@
+package indexer
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sap-go/annfile"
+	"sap-go/protocol"
+	"testing"
+)
+
+// TestIndex stores announcements of two protocols over a few blocks in a
+// fresh database and checks that they survive reopening, that block ranges
+// return them in order, that a scan finds the recipient's payments and
+// resumes from its stored cursor, and that invalid entries are rejected.
+func TestIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "index.db")
+
+	p, other := protocol.ECPDKSAPBN254(), protocol.DKSAPSecp256k1()
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// entries creates two announcements of p per block, the first paying r
+	// in paid blocks, and one announcement of other in even blocks.
+	entries := func(from, to uint64, paid map[uint64]bool) ([]Entry, error) {
+		var out []Entry
+		for block := from; block <= to; block++ {
+			for i := uint32(0); i < 3; i++ {
+				q := p
+				var ann protocol.Announcement
+				var err error
+				switch {
+				case i == 0 && paid[block]:
+					ann, err = p.Send(r.MetaAddress())
+				case i == 2 && block%2 == 1:
+					continue
+				case i == 2:
+					q = other
+					ann, err = other.RandomAnnouncement()
+				default:
+					ann, err = p.RandomAnnouncement()
+				}
+				if err != nil {
+					return nil, err
+				}
+				e := Entry{Position: Position{Block: block, LogIndex: i}, Record: annfile.NewRecord(q, ann)}
+				e.TxHash[0], e.TxHash[1] = byte(block), byte(i)
+				out = append(out, e)
+			}
+		}
+		return out, nil
+	}
+
+	first, err := entries(10, 15, map[uint64]bool{11: true, 14: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Put(first...); err != nil {
+		ix.Close()
+		t.Fatalf("Put: %v", err)
+	}
+	if err := ix.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ix.Close()
+	if n, err := ix.Len(); err != nil || n != len(first) {
+		t.Fatalf("Len after reopening: got %d, expected %d (err: %v)", n, len(first), err)
+	}
+	if latest, ok, err := ix.Latest(); err != nil || !ok || latest != first[len(first)-1].Position {
+		t.Fatalf("Latest: got %v (ok: %t, err: %v)", latest, ok, err)
+	}
+
+	var ranged []Entry
+	if err := ix.Range(12, 13, func(e Entry) error { ranged = append(ranged, e); return nil }); err != nil {
+		t.Fatalf("Range: %v", err)
+	}
+	var expected []Entry
+	for _, e := range first {
+		if e.Block >= 12 && e.Block <= 13 {
+			expected = append(expected, e)
+		}
+	}
+	if !reflect.DeepEqual(ranged, expected) {
+		t.Fatalf("Range(12, 13): got %d entries, expected %d", len(ranged), len(expected))
+	}
+
+	// The first scan reads every announcement of p, the second only those stored since.
+	found, stats, err := ix.Scan("r", p, r, 2, true)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if blocks := entryBlocks(found); !reflect.DeepEqual(blocks, []uint64{11, 14}) || stats.Scanned != 12 {
+		t.Fatalf("Scan: found blocks %v after %d announcements, expected [11 14] after 12", blocks, stats.Scanned)
+	}
+	if cursor, err := ix.Cursor("r"); err != nil || cursor != (Position{Block: 15, LogIndex: 2}) {
+		t.Fatalf("Cursor: got %v (err: %v)", cursor, err)
+	}
+	second, err := entries(16, 17, map[uint64]bool{17: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Put(second...); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	found, stats, err = ix.Scan("r", p, r, 2, true)
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if blocks := entryBlocks(found); !reflect.DeepEqual(blocks, []uint64{17}) || stats.Scanned != 4 {
+		t.Fatalf("resumed Scan: found blocks %v after %d announcements, expected [17] after 4", blocks, stats.Scanned)
+	}
+	if found, _, err := ix.Scan("fresh", p, r, 2, false); err != nil || !reflect.DeepEqual(entryBlocks(found), []uint64{11, 14, 17}) {
+		t.Fatalf("Scan with a new cursor: found blocks %v (err: %v)", entryBlocks(found), err)
+	}
+
+	invalid := second[0]
+	invalid.Position = Position{Block: 18}
+	invalid.Ephemeral = invalid.Ephemeral[1:]
+	if err := ix.Put(second[1], invalid); !errors.Is(err, protocol.ErrInvalidEphemeral) {
+		t.Fatalf("Put: truncated ephemeral key: got %v, expected %v", err, protocol.ErrInvalidEphemeral)
+	}
+	if latest, _, _ := ix.Latest(); latest != second[len(second)-1].Position {
+		t.Fatal("Put: a rejected batch was partly stored")
+	}
+}
+
+func entryBlocks(entries []Entry) []uint64 {
+	var blocks []uint64
+	for _, e := range entries {
+		blocks = append(blocks, e.Block)
+	}
+	return blocks
+}
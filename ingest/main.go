@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sap-go/erc5564"
+	"sap-go/indexer"
+	"time"
+)
+
+func main() {
+	rpcURL := flag.String("rpc", "http://localhost:8545", "Ethereum JSON-RPC endpoint")
+	contract := flag.String("contract", "", "address of the ERC5564Announcer contract")
+	db := flag.String("db", "index.db", "announcement index database")
+	from := flag.Uint64("from", 0, "first block to read when the index has no progress for the contract")
+	page := flag.Uint64("page", erc5564.DefaultPageSize, "blocks per eth_getLogs request")
+	confirmations := flag.Uint64("confirmations", 12, "number of latest blocks to leave unread")
+	follow := flag.Duration("follow", 0, "keep polling for new blocks at this interval")
+	flag.Parse()
+
+	if *contract == "" {
+		fmt.Println("Error: -contract is required")
+		os.Exit(1)
+	}
+	ix, err := indexer.Open(*db)
+	if err != nil {
+		fmt.Println("Error opening index:", err)
+		os.Exit(1)
+	}
+	defer ix.Close()
+
+	in := &erc5564.Ingester{
+		Client:        erc5564.NewClient(*rpcURL),
+		Index:         ix,
+		Contract:      *contract,
+		Start:         *from,
+		PageSize:      *page,
+		Confirmations: *confirmations,
+	}
+	for {
+		stats, err := in.Sync(context.Background())
+		if err != nil {
+			fmt.Println("Error ingesting:", err)
+			ix.Close()
+			os.Exit(1)
+		}
+		cursor, err := ix.Cursor(erc5564.CursorName(*contract))
+		if err != nil {
+			fmt.Println("Error reading progress:", err)
+			ix.Close()
+			os.Exit(1)
+		}
+		fmt.Printf("Read %d blocks, next block %d: %d announcements stored, %d logs skipped, %d reorganisations\n",
+			stats.Blocks, cursor.Block, stats.Stored, stats.Skipped, stats.Reorgs)
+		if stats.Secp256k1 > 0 {
+			fmt.Printf("Skipped %d announcements of ERC-5564 scheme 1 (secp256k1 with Keccak-256), which no protocol here can scan\n", stats.Secp256k1)
+		}
+		if *follow <= 0 {
+			return
+		}
+		time.Sleep(*follow)
+	}
+}
@@ -0,0 +1,245 @@
+// Package codectest provides crafted invalid points of every curve for tests
+// of the codec and of the protocols decoding ephemeral keys.
+package codectest
+
+import (
+	"sap-go/codec"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bls12377fp "github.com/consensys/gnark-crypto/ecc/bls12-377/fp"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	bn254fp "github.com/consensys/gnark-crypto/ecc/bn254/fp"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	secp256k1fp "github.com/consensys/gnark-crypto/ecc/secp256k1/fp"
+)
+
+// The curve constants b of G1 and of the twist, recovered from the generators.
+var bn254G1B, bn254G2B = func() (bn254fp.Element, bn254.E2) {
+	_, _, g1, g2 := bn254.Generators()
+	var b1, x3 bn254fp.Element
+	b1.Square(&g1.Y)
+	x3.Square(&g1.X).Mul(&x3, &g1.X)
+	b1.Sub(&b1, &x3)
+
+	var b2, x3Twist bn254.E2
+	b2.Square(&g2.Y)
+	x3Twist.Square(&g2.X).Mul(&x3Twist, &g2.X)
+	b2.Sub(&b2, &x3Twist)
+	return b1, b2
+}()
+
+var bls12377G1B, bls12377G2B = func() (bls12377fp.Element, bls12377.E2) {
+	_, _, g1, g2 := bls12377.Generators()
+	var b1, x3 bls12377fp.Element
+	b1.Square(&g1.Y)
+	x3.Square(&g1.X).Mul(&x3, &g1.X)
+	b1.Sub(&b1, &x3)
+
+	var b2, x3Twist bls12377.E2
+	b2.Square(&g2.Y)
+	x3Twist.Square(&g2.X).Mul(&x3Twist, &g2.X)
+	b2.Sub(&b2, &x3Twist)
+	return b1, b2
+}()
+
+// Invalid is a crafted point that codec.Validate rejects with Err. Compressed is
+// its compressed encoding, which decoding rejects with the same error, or
+// nil when the curve's encoding cannot express the point.
+type Invalid[T any] struct {
+	Name       string
+	Point      T
+	Compressed []byte
+	Err        error
+}
+
+// InvalidBN254G1 returns the identity and a point off the curve. The cofactor
+// of G1 is 1, so every point on the curve is in the subgroup.
+func InvalidBN254G1() []Invalid[bn254.G1Affine] {
+	var identity bn254.G1Affine
+	offCurve := bn254.G1Affine{X: bn254G1OffCurveX()}
+	return []Invalid[bn254.G1Affine]{
+		{"identity", identity, bn254G1Compressed(&identity), codec.ErrIdentity},
+		{"off curve", offCurve, bn254G1Compressed(&offCurve), codec.ErrNotOnCurve},
+	}
+}
+
+// InvalidBN254G2 returns the identity, a point off the twist and a point on
+// the twist outside the prime-order subgroup.
+func InvalidBN254G2() []Invalid[bn254.G2Affine] {
+	var identity bn254.G2Affine
+	offCurve := bn254.G2Affine{X: bn254G2OffCurveX()}
+	twist := BN254G2OutsideSubgroup()
+	return []Invalid[bn254.G2Affine]{
+		{"identity", identity, bn254G2Compressed(&identity), codec.ErrIdentity},
+		{"off curve", offCurve, bn254G2Compressed(&offCurve), codec.ErrNotOnCurve},
+		{"outside subgroup", twist, bn254G2Compressed(&twist), codec.ErrNotInSubgroup},
+	}
+}
+
+// InvalidBLS12377G1 returns the identity, a point off the curve and a point
+// on the curve outside the prime-order subgroup.
+func InvalidBLS12377G1() []Invalid[bls12377.G1Affine] {
+	var identity bls12377.G1Affine
+	offCurve := bls12377.G1Affine{X: bls12377G1OffCurveX()}
+	curve := BLS12377G1OutsideSubgroup()
+	return []Invalid[bls12377.G1Affine]{
+		{"identity", identity, bls12377G1Compressed(&identity), codec.ErrIdentity},
+		{"off curve", offCurve, bls12377G1Compressed(&offCurve), codec.ErrNotOnCurve},
+		{"outside subgroup", curve, bls12377G1Compressed(&curve), codec.ErrNotInSubgroup},
+	}
+}
+
+// InvalidBLS12377G2 returns the identity, a point off the twist and a point
+// on the twist outside the prime-order subgroup.
+func InvalidBLS12377G2() []Invalid[bls12377.G2Affine] {
+	var identity bls12377.G2Affine
+	offCurve := bls12377.G2Affine{X: bls12377G2OffCurveX()}
+	twist := BLS12377G2OutsideSubgroup()
+	return []Invalid[bls12377.G2Affine]{
+		{"identity", identity, bls12377G2Compressed(&identity), codec.ErrIdentity},
+		{"off curve", offCurve, bls12377G2Compressed(&offCurve), codec.ErrNotOnCurve},
+		{"outside subgroup", twist, bls12377G2Compressed(&twist), codec.ErrNotInSubgroup},
+	}
+}
+
+// InvalidSecp256k1 returns the identity, which SEC1 compressed points cannot
+// encode, and a point off the curve.
+func InvalidSecp256k1() []Invalid[secp256k1.G1Affine] {
+	var identity secp256k1.G1Affine
+	offCurve := secp256k1.G1Affine{X: secp256k1OffCurveX()}
+	return []Invalid[secp256k1.G1Affine]{
+		{"identity", identity, nil, codec.ErrIdentity},
+		{"off curve", offCurve, codec.EncodeSecp256k1(&offCurve, true), codec.ErrNotOnCurve},
+	}
+}
+
+func bn254G1Compressed(p *bn254.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func bn254G2Compressed(p *bn254.G2Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func bls12377G1Compressed(p *bls12377.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func bls12377G2Compressed(p *bls12377.G2Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+// The searches below walk x = 1, 2, … (x = i + u on the twists) until
+// x^3 + b is a non-residue, or a residue whose point is outside the subgroup.
+
+func bn254G1OffCurveX() bn254fp.Element {
+	var x, y2 bn254fp.Element
+	for {
+		x.Add(&x, new(bn254fp.Element).SetOne())
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &bn254G1B)
+		if y2.Legendre() == -1 {
+			return x
+		}
+	}
+}
+
+func bn254G2OffCurveX() bn254.E2 {
+	var x, y2 bn254.E2
+	x.A1.SetOne()
+	for {
+		x.A0.Add(&x.A0, new(bn254fp.Element).SetOne())
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &bn254G2B)
+		if y2.Legendre() == -1 {
+			return x
+		}
+	}
+}
+
+// BN254G2OutsideSubgroup returns a point on the BN254 twist outside the prime-order subgroup.
+func BN254G2OutsideSubgroup() bn254.G2Affine {
+	var p bn254.G2Affine
+	var y2 bn254.E2
+	p.X.A1.SetOne()
+	for {
+		p.X.A0.Add(&p.X.A0, new(bn254fp.Element).SetOne())
+		y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bn254G2B)
+		if y2.Legendre() == 1 {
+			p.Y.Sqrt(&y2)
+			if !p.IsInSubGroup() {
+				return p
+			}
+		}
+	}
+}
+
+func bls12377G1OffCurveX() bls12377fp.Element {
+	var x, y2 bls12377fp.Element
+	for {
+		x.Add(&x, new(bls12377fp.Element).SetOne())
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &bls12377G1B)
+		if y2.Legendre() == -1 {
+			return x
+		}
+	}
+}
+
+// BLS12377G1OutsideSubgroup returns a point on BLS12-377 outside the prime-order subgroup.
+func BLS12377G1OutsideSubgroup() bls12377.G1Affine {
+	var p bls12377.G1Affine
+	var y2 bls12377fp.Element
+	for {
+		p.X.Add(&p.X, new(bls12377fp.Element).SetOne())
+		y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bls12377G1B)
+		if y2.Legendre() == 1 {
+			p.Y.Sqrt(&y2)
+			if !p.IsInSubGroup() {
+				return p
+			}
+		}
+	}
+}
+
+func bls12377G2OffCurveX() bls12377.E2 {
+	var x, y2 bls12377.E2
+	x.A1.SetOne()
+	for {
+		x.A0.Add(&x.A0, new(bls12377fp.Element).SetOne())
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &bls12377G2B)
+		if y2.Legendre() == -1 {
+			return x
+		}
+	}
+}
+
+// BLS12377G2OutsideSubgroup returns a point on the BLS12-377 twist outside the prime-order subgroup.
+func BLS12377G2OutsideSubgroup() bls12377.G2Affine {
+	var p bls12377.G2Affine
+	var y2 bls12377.E2
+	p.X.A1.SetOne()
+	for {
+		p.X.A0.Add(&p.X.A0, new(bls12377fp.Element).SetOne())
+		y2.Square(&p.X).Mul(&y2, &p.X).Add(&y2, &bls12377G2B)
+		if y2.Legendre() == 1 {
+			p.Y.Sqrt(&y2)
+			if !p.IsInSubGroup() {
+				return p
+			}
+		}
+	}
+}
+
+func secp256k1OffCurveX() secp256k1fp.Element {
+	var x, y2, seven secp256k1fp.Element
+	seven.SetUint64(7)
+	for {
+		x.Add(&x, new(secp256k1fp.Element).SetOne())
+		y2.Square(&x).Mul(&y2, &x).Add(&y2, &seven)
+		if y2.Legendre() == -1 {
+			return x
+		}
+	}
+}
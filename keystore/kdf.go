@@ -0,0 +1,95 @@
+package keystore
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Names of the supported key derivation functions.
+const (
+	KDFScrypt   = "scrypt"
+	KDFArgon2id = "argon2id"
+)
+
+const (
+	saltSize = 32
+	keySize  = 32
+)
+
+// Upper bounds on the KDF cost accepted from a keystore file, so a crafted
+// file cannot make decryption exhaust memory or CPU. Scrypt needs 128·N·r
+// bytes per lane and runs its p lanes in sequence, so the product 128·N·r·p
+// bounds both.
+const (
+	maxScryptN       = 1 << 22
+	maxScryptParam   = 64
+	maxScryptCost    = 1 << 30 // bytes, 128·N·r·p
+	maxArgon2Mem     = 1 << 20 // KiB
+	maxArgon2Time    = 64
+	maxArgon2Threads = 16
+)
+
+// KDF is a password-based key derivation function and its parameters.
+// Memory is in KiB.
+type KDF struct {
+	Name    string `json:"name"`
+	Salt    Bytes  `json:"salt"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// Scrypt returns scrypt with the given cost parameters.
+func Scrypt(n, r, p int) KDF {
+	return KDF{Name: KDFScrypt, N: n, R: r, P: p}
+}
+
+// Argon2id returns Argon2id with the given number of passes, memory in KiB
+// and parallelism.
+func Argon2id(time, memory uint32, threads uint8) KDF {
+	return KDF{Name: KDFArgon2id, Time: time, Memory: memory, Threads: threads}
+}
+
+// DefaultScrypt is scrypt with the interactive-login parameters recommended
+// for 2017 hardware, about 256 MiB of memory.
+func DefaultScrypt() KDF { return Scrypt(1<<18, 8, 1) }
+
+// DefaultArgon2id is Argon2id with the RFC 9106 second recommended option.
+func DefaultArgon2id() KDF { return Argon2id(3, 64<<10, 4) }
+
+// ParseKDF returns the default parameters of the named KDF.
+func ParseKDF(name string) (KDF, error) {
+	switch name {
+	case KDFScrypt:
+		return DefaultScrypt(), nil
+	case KDFArgon2id:
+		return DefaultArgon2id(), nil
+	}
+	return KDF{}, fmt.Errorf("unknown KDF %q, expected %s or %s", name, KDFScrypt, KDFArgon2id)
+}
+
+// derive stretches password into an encryption key.
+func (k KDF) derive(password []byte) ([]byte, error) {
+	if len(k.Salt) < 16 {
+		return nil, fmt.Errorf("%w: salt of %d bytes", ErrFormat, len(k.Salt))
+	}
+	switch k.Name {
+	case KDFScrypt:
+		if k.N < 2 || k.N > maxScryptN || k.N&(k.N-1) != 0 || k.R < 1 || k.R > maxScryptParam || k.P < 1 || k.P > maxScryptParam ||
+			128*k.N*k.R*k.P > maxScryptCost {
+			return nil, fmt.Errorf("%w: scrypt parameters N=%d r=%d p=%d", ErrFormat, k.N, k.R, k.P)
+		}
+		return scrypt.Key(password, k.Salt, k.N, k.R, k.P, keySize)
+	case KDFArgon2id:
+		if k.Time < 1 || k.Time > maxArgon2Time || k.Memory < 8 || k.Memory > maxArgon2Mem || k.Threads < 1 || k.Threads > maxArgon2Threads {
+			return nil, fmt.Errorf("%w: argon2id parameters time=%d memory=%d threads=%d", ErrFormat, k.Time, k.Memory, k.Threads)
+		}
+		return argon2.IDKey(password, k.Salt, k.Time, k.Memory, k.Threads, keySize), nil
+	}
+	return nil, fmt.Errorf("%w: unsupported KDF %q", ErrFormat, k.Name)
+}
@@ -0,0 +1,263 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sap-go/protocol"
+)
+
+// Version is the version of the keystore format written by Encrypt.
+const Version = 1
+
+// Cipher is the only supported authenticated encryption scheme.
+const Cipher = "aes-256-gcm"
+
+var (
+	// ErrFormat is returned when a file is not a valid keystore.
+	ErrFormat = errors.New("invalid keystore")
+	// ErrPassword is returned when the keystore cannot be decrypted, either
+	// because the password is wrong or the file was modified.
+	ErrPassword = errors.New("wrong password or corrupted keystore")
+)
+
+// Bytes is a byte slice encoded as hex in JSON.
+type Bytes []byte
+
+func (b Bytes) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+func (b *Bytes) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// MetaAddress is the public part of the stored keys.
+type MetaAddress struct {
+	Spend Bytes `json:"spend"`
+	View  Bytes `json:"view"`
+}
+
+// Crypto holds the encrypted keys and how to decrypt them.
+type Crypto struct {
+	KDF        KDF    `json:"kdf"`
+	Cipher     string `json:"cipher"`
+	Nonce      Bytes  `json:"nonce"`
+	Ciphertext Bytes  `json:"ciphertext"`
+}
+
+// Keystore is a recipient's private keys for one protocol, encrypted under a
+// password. A view-only keystore holds the viewing key only: it can scan for
+// announcements but cannot derive stealth private keys. The header is
+// authenticated together with the keys.
+type Keystore struct {
+	Version     int         `json:"version"`
+	Protocol    string      `json:"protocol"`
+	Curve       string      `json:"curve"`
+	ViewOnly    bool        `json:"viewOnly"`
+	MetaAddress MetaAddress `json:"metaAddress"`
+	Crypto      Crypto      `json:"crypto"`
+}
+
+// Encrypt stores the keys of r under password. View-only recipients produce
+// a view-only keystore.
+func Encrypt(p protocol.Protocol, r protocol.Recipient, password []byte, kdf KDF) (*Keystore, error) {
+	return encrypt(p, r, r.SpendingKey(), password, kdf)
+}
+
+// EncryptViewOnly stores only the viewing key of r under password, so the
+// keystore can be handed to a scanning service without the spending key.
+func EncryptViewOnly(p protocol.Protocol, r protocol.Recipient, password []byte, kdf KDF) (*Keystore, error) {
+	return encrypt(p, r, nil, password, kdf)
+}
+
+func encrypt(p protocol.Protocol, r protocol.Recipient, spendingKey, password []byte, kdf KDF) (*Keystore, error) {
+	meta := r.MetaAddress()
+	ks := &Keystore{
+		Version:     Version,
+		Protocol:    p.Name(),
+		Curve:       p.Curve(),
+		ViewOnly:    spendingKey == nil,
+		MetaAddress: MetaAddress{Spend: meta.Spend, View: meta.View},
+		Crypto:      Crypto{KDF: kdf, Cipher: Cipher},
+	}
+	ks.Crypto.KDF.Salt = make(Bytes, saltSize)
+	if _, err := rand.Read(ks.Crypto.KDF.Salt); err != nil {
+		return nil, fmt.Errorf("error generating salt: %w", err)
+	}
+
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, err
+	}
+	ks.Crypto.Nonce = make(Bytes, aead.NonceSize())
+	if _, err := rand.Read(ks.Crypto.Nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	plaintext := encodeKeys(spendingKey, r.ViewingKey())
+	ks.Crypto.Ciphertext = aead.Seal(nil, ks.Crypto.Nonce, plaintext, ks.associatedData())
+	clear(plaintext)
+	return ks, nil
+}
+
+// Decrypt restores the recipient stored in ks. View-only keystores restore a
+// recipient created with NewViewOnlyRecipient.
+func (ks *Keystore) Decrypt(password []byte) (protocol.Protocol, protocol.Recipient, error) {
+	p, err := ks.check()
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := ks.aead(password)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ks.Crypto.Nonce) != aead.NonceSize() {
+		return nil, nil, fmt.Errorf("%w: nonce of %d bytes", ErrFormat, len(ks.Crypto.Nonce))
+	}
+	plaintext, err := aead.Open(nil, ks.Crypto.Nonce, ks.Crypto.Ciphertext, ks.associatedData())
+	if err != nil {
+		return nil, nil, ErrPassword
+	}
+	defer clear(plaintext)
+	spendingKey, viewingKey, err := decodeKeys(plaintext)
+	if err != nil {
+		return nil, nil, err
+	}
+	if (spendingKey == nil) != ks.ViewOnly {
+		return nil, nil, fmt.Errorf("%w: spending key does not match the view-only flag", ErrFormat)
+	}
+
+	var r protocol.Recipient
+	if ks.ViewOnly {
+		r, err = p.NewViewOnlyRecipient(ks.MetaAddress.Spend, viewingKey)
+	} else {
+		r, err = p.NewRecipient(spendingKey, viewingKey)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	meta := r.MetaAddress()
+	if !bytes.Equal(meta.Spend, ks.MetaAddress.Spend) || !bytes.Equal(meta.View, ks.MetaAddress.View) {
+		return nil, nil, fmt.Errorf("%w: keys do not match the meta address", ErrFormat)
+	}
+	return p, r, nil
+}
+
+// check validates the header and returns the keystore's protocol.
+func (ks *Keystore) check() (protocol.Protocol, error) {
+	if ks.Version != Version {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrFormat, ks.Version)
+	}
+	if ks.Crypto.Cipher != Cipher {
+		return nil, fmt.Errorf("%w: unsupported cipher %q", ErrFormat, ks.Crypto.Cipher)
+	}
+	p, err := protocol.Lookup(ks.Protocol, ks.Curve)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	return p, nil
+}
+
+// aead derives the encryption key from password.
+func (ks *Keystore) aead(password []byte) (cipher.AEAD, error) {
+	key, err := ks.Crypto.KDF.derive(password)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// associatedData binds the header to the ciphertext, so the protocol, the
+// view-only flag and the meta address cannot be swapped.
+func (ks *Keystore) associatedData() []byte {
+	return []byte(fmt.Sprintf("sap-keystore|%d|%s|%s|%t|%x|%x|%s", ks.Version, ks.Protocol, ks.Curve,
+		ks.ViewOnly, ks.MetaAddress.Spend, ks.MetaAddress.View, ks.Crypto.KDF.Name))
+}
+
+// encodeKeys concatenates the length-prefixed spending and viewing keys. An
+// empty spending key marks a view-only keystore.
+func encodeKeys(spendingKey, viewingKey []byte) []byte {
+	b := make([]byte, 0, 2+len(spendingKey)+len(viewingKey))
+	b = append(b, byte(len(spendingKey)))
+	b = append(b, spendingKey...)
+	b = append(b, byte(len(viewingKey)))
+	return append(b, viewingKey...)
+}
+
+func decodeKeys(b []byte) (spendingKey, viewingKey []byte, err error) {
+	var keys [2][]byte
+	for i := range keys {
+		if len(b) < 1 || len(b) < 1+int(b[0]) {
+			return nil, nil, fmt.Errorf("%w: truncated keys", ErrFormat)
+		}
+		if b[0] > 0 {
+			keys[i] = bytes.Clone(b[1 : 1+int(b[0])])
+		}
+		b = b[1+int(b[0]):]
+	}
+	if len(b) != 0 || keys[1] == nil {
+		return nil, nil, fmt.Errorf("%w: malformed keys", ErrFormat)
+	}
+	return keys[0], keys[1], nil
+}
+
+// Write encodes ks as indented JSON.
+func Write(w io.Writer, ks *Keystore) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ks)
+}
+
+// Read decodes a keystore written by Write.
+func Read(r io.Reader) (*Keystore, error) {
+	var ks Keystore
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&ks); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFormat, err)
+	}
+	if _, err := ks.check(); err != nil {
+		return nil, err
+	}
+	return &ks, nil
+}
+
+// Save writes ks to a new file at path that only the owner can read.
+func Save(path string, ks *Keystore) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, ks); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Load reads the keystore file at path.
+func Load(path string) (*Keystore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Read(file)
+}
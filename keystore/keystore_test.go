@@ -0,0 +1,106 @@
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"sap-go/protocol"
+	"testing"
+)
+
+// TestRoundTrip stores a recipient of every protocol in full and view-only keystores with
+// cheap KDF parameters and checks that the keys round trip, that view-only
+// keystores hold no spending key and that wrong passwords and modified
+// headers or ciphertexts are rejected.
+func TestRoundTrip(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testRoundTrip(t, p) })
+	}
+}
+
+func testRoundTrip(t *testing.T, p protocol.Protocol) {
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := []byte("correct horse battery staple")
+
+	for _, kdf := range []KDF{Scrypt(1<<10, 8, 1), Argon2id(1, 64, 1)} {
+		ks, err := Encrypt(p, r, password, kdf)
+		if err != nil {
+			t.Fatalf("%s: Encrypt: %v", kdf.Name, err)
+		}
+		var buf bytes.Buffer
+		if err := Write(&buf, ks); err != nil {
+			t.Fatalf("%s: Write: %v", kdf.Name, err)
+		}
+		if ks, err = Read(&buf); err != nil {
+			t.Fatalf("%s: Read: %v", kdf.Name, err)
+		}
+		_, restored, err := ks.Decrypt(password)
+		if err != nil {
+			t.Fatalf("%s: Decrypt: %v", kdf.Name, err)
+		}
+		if !bytes.Equal(restored.SpendingKey(), r.SpendingKey()) || !bytes.Equal(restored.ViewingKey(), r.ViewingKey()) {
+			t.Fatalf("%s: Decrypt: restored keys differ", kdf.Name)
+		}
+		if _, _, err := ks.Decrypt([]byte("wrong password")); !errors.Is(err, ErrPassword) {
+			t.Fatalf("%s: Decrypt: wrong password: got %v, expected %v", kdf.Name, err, ErrPassword)
+		}
+	}
+
+	kdf := Scrypt(1<<10, 8, 1)
+	viewOnly, err := EncryptViewOnly(p, r, password, kdf)
+	if err != nil {
+		t.Fatalf("EncryptViewOnly: %v", err)
+	}
+	_, restored, err := viewOnly.Decrypt(password)
+	if err != nil {
+		t.Fatalf("view-only Decrypt: %v", err)
+	}
+	if !viewOnly.ViewOnly || restored.SpendingKey() != nil || !bytes.Equal(restored.ViewingKey(), r.ViewingKey()) {
+		t.Fatal("view-only Decrypt: spending key present or viewing key differs")
+	}
+
+	full, err := Encrypt(p, r, password, kdf)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	flipped := *full
+	flipped.ViewOnly = true
+	tampered := *full
+	tampered.Crypto.Ciphertext = bytes.Clone(full.Crypto.Ciphertext)
+	tampered.Crypto.Ciphertext[0] ^= 1
+	for name, ks := range map[string]*Keystore{"view-only flag": &flipped, "ciphertext": &tampered} {
+		if _, _, err := ks.Decrypt(password); !errors.Is(err, ErrPassword) {
+			t.Fatalf("Decrypt: modified %s: got %v, expected %v", name, err, ErrPassword)
+		}
+	}
+}
+
+// TestLimits checks that KDF parameters beyond the resource limits are
+// rejected before any key is derived.
+func TestLimits(t *testing.T) {
+	p := protocol.All()[0]
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := []byte("correct horse battery staple")
+	for _, kdf := range []KDF{
+		Scrypt(1<<22, 64, 64),
+		Scrypt(1<<20, 8, 2),
+		Scrypt(1<<21, 8, 1),
+		Argon2id(1, 4<<20, 1),
+		Argon2id(1, 64, 255),
+	} {
+		ks, err := Encrypt(p, r, password, Scrypt(1<<10, 8, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		kdf.Salt = ks.Crypto.KDF.Salt
+		ks.Crypto.KDF = kdf
+		if _, _, err := ks.Decrypt(password); !errors.Is(err, ErrFormat) {
+			t.Fatalf("Decrypt with %+v: got %v, expected %v", kdf, err, ErrFormat)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mnemonic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"sap-go/hdkey"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// english is the BIP-39 English wordlist.
+//
+//go:embed english.txt
+var english string
+
+var (
+	words = strings.Fields(english)
+	index = func() map[string]int {
+		m := make(map[string]int, len(words))
+		for i, w := range words {
+			m[w] = i
+		}
+		return m
+	}()
+)
+
+var (
+	// ErrEntropy is returned for entropy sizes BIP-39 does not define.
+	ErrEntropy = errors.New("entropy must be 128, 160, 192, 224 or 256 bits")
+	// ErrWordCount is returned for mnemonics that are not 12, 15, 18, 21 or 24 words long.
+	ErrWordCount = errors.New("mnemonic must have 12, 15, 18, 21 or 24 words")
+	// ErrUnknownWord is returned for words outside the English wordlist.
+	ErrUnknownWord = errors.New("word not in the BIP-39 English wordlist")
+	// ErrChecksum is returned when the checksum bits do not match the entropy.
+	ErrChecksum = errors.New("mnemonic checksum mismatch")
+)
+
+// Generate returns a mnemonic encoding bits of fresh entropy.
+func Generate(bits int) (string, error) {
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", ErrEntropy
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", fmt.Errorf("error generating entropy: %w", err)
+	}
+	return FromEntropy(entropy)
+}
+
+// FromEntropy encodes entropy and its SHA-256 checksum as words of 11 bits.
+func FromEntropy(entropy []byte) (string, error) {
+	bits := 8 * len(entropy)
+	if bits%32 != 0 || bits < 128 || bits > 256 {
+		return "", ErrEntropy
+	}
+	checksumBits := bits / 32
+	hash := sha256.Sum256(entropy)
+	n := new(big.Int).SetBytes(entropy)
+	n.Lsh(n, uint(checksumBits))
+	n.Or(n, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	count := (bits + checksumBits) / 11
+	out := make([]string, count)
+	mask := big.NewInt(1<<11 - 1)
+	for i := count - 1; i >= 0; i-- {
+		out[i] = words[new(big.Int).And(n, mask).Int64()]
+		n.Rsh(n, 11)
+	}
+	return strings.Join(out, " "), nil
+}
+
+// Entropy decodes a mnemonic and verifies its checksum.
+func Entropy(mnemonic string) ([]byte, error) {
+	fields := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(fields)%3 != 0 || len(fields) < 12 || len(fields) > 24 {
+		return nil, fmt.Errorf("%w: got %d", ErrWordCount, len(fields))
+	}
+	n := new(big.Int)
+	for i, w := range fields {
+		j, ok := index[w]
+		if !ok {
+			return nil, fmt.Errorf("%w: word %d %q", ErrUnknownWord, i+1, w)
+		}
+		n.Lsh(n, 11).Or(n, big.NewInt(int64(j)))
+	}
+
+	checksumBits := len(fields) * 11 / 33
+	checksum := new(big.Int).And(n, big.NewInt(1<<checksumBits-1)).Int64()
+	entropy := n.Rsh(n, uint(checksumBits)).FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, ErrChecksum
+	}
+	return entropy, nil
+}
+
+// Validate reports whether mnemonic is well formed with a valid checksum.
+func Validate(mnemonic string) error {
+	_, err := Entropy(mnemonic)
+	return err
+}
+
+// Seed validates mnemonic and stretches it with passphrase into the seed
+// used by hdkey.
+func Seed(mnemonic, passphrase string) ([]byte, error) {
+	if err := Validate(mnemonic); err != nil {
+		return nil, err
+	}
+	return hdkey.SeedFromMnemonic(mnemonic, passphrase), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sap-go/bench"
+	"sap-go/chart"
+	"sap-go/config"
+	"sap-go/harness"
+	"sap-go/protocol"
+	"sap-go/results"
+)
+
+func main() {
+	n := flag.Int("n", config.RunNumber, "number of announcements to scan")
+	owned := flag.Int("owned", 1, "number of announcements addressed to the recipient")
+	runs := flag.Int("runs", 5, "number of runs per GOMAXPROCS setting")
+	maxProcs := flag.Int("max", runtime.NumCPU(), "largest GOMAXPROCS setting")
+	protocolName := flag.String("protocol", "", "only run this protocol (default all)")
+	curveName := flag.String("curve", "", "only run this curve (default all)")
+	outDir := flag.String("out", "multicore_results", "directory for the CSV file and charts")
+	flag.Parse()
+
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fmt.Println("Error creating output directory:", err)
+		os.Exit(1)
+	}
+
+	procs := harness.Procs(*maxProcs)
+	var summaries []results.CoreSummary
+	for _, p := range protocol.All() {
+		if (*protocolName != "" && p.Name() != *protocolName) || (*curveName != "" && p.Curve() != *curveName) {
+			continue
+		}
+		fixture, err := harness.NewFixture(p, &bench.Options{Announcements: *n, Owned: *owned})
+		if err != nil {
+			fmt.Println("Error preparing announcements:", err)
+			os.Exit(1)
+		}
+		protocolSummaries, err := harness.Multicore(p, fixture, procs, *runs)
+		if err != nil {
+			fmt.Printf("Error scanning %s: %v\n", protocol.ID(p), err)
+			os.Exit(1)
+		}
+		summaries = append(summaries, protocolSummaries...)
+	}
+	if len(summaries) == 0 {
+		fmt.Println("No protocol matches -protocol and -curve")
+		os.Exit(2)
+	}
+
+	fmt.Printf("%-20s %-10s %10s %12s %8s %10s\n", "Protocol", "Curve", "GOMAXPROCS", "Mean (ms)", "Speedup", "Efficiency")
+	for _, s := range summaries {
+		fmt.Printf("%-20s %-10s %10d %12.2f %7.2fx %9.0f%%\n", s.Protocol, s.Curve, s.Procs, s.Mean, s.Speedup, s.Efficiency*100)
+	}
+
+	fileName := filepath.Join(*outDir, results.MulticoreFileName(*n))
+	if err := results.SaveCores(fileName, summaries); err != nil {
+		fmt.Println("Error writing CSV file:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Multi-core results saved to", fileName)
+
+	charts, err := chart.WriteMulticoreCharts(summaries, *outDir)
+	if err != nil {
+		fmt.Println("Error writing charts:", err)
+		os.Exit(1)
+	}
+	for _, c := range charts {
+		fmt.Println("Chart saved to", c)
+	}
+}
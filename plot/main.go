@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sap-go/chart"
+	"sap-go/results"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory containing experiment_results_*.csv files")
+	outDir := flag.String("out", ".", "directory the SVG charts are written to")
+	protocol := flag.String("protocol", "ecpdksap", "protocol assumed for result files without a Protocol column")
+	curve := flag.String("curve", "bn254", "curve assumed for result files without a Curve column")
+	flag.Parse()
+
+	records, err := results.LoadDir(*dir, *protocol, *curve)
+	if err != nil {
+		fmt.Println("Error loading experiment results:", err)
+		os.Exit(1)
+	}
+
+	summaries := results.Summarize(records)
+	for _, s := range summaries {
+		fmt.Printf("%-24s %8d keys  %10.2f ms ± %.2f (%d runs)\n", s.Series(), s.PublicKeys, s.Mean, s.StdDev, s.Runs)
+	}
+
+	paths, err := chart.WriteResultCharts(summaries, *outDir)
+	if err != nil {
+		fmt.Println("Error writing charts:", err)
+		os.Exit(1)
+	}
+	for _, path := range paths {
+		fmt.Println("Chart saved to", path)
+	}
+}
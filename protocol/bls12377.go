@@ -0,0 +1,355 @@
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"sap-go/codec"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	"github.com/consensys/gnark-crypto/ecc/bls12-377/fr"
+)
+
+var bls12377G1Gen, bls12377G2Gen = func() (bls12377.G1Affine, bls12377.G2Affine) {
+	_, _, g1Gen, g2Gen := bls12377.Generators()
+	return g1Gen, g2Gen
+}()
+
+// bls12377Scalar is a private scalar together with its big.Int form.
+type bls12377Scalar struct {
+	element fr.Element
+	bigInt  big.Int
+}
+
+func newBLS12377Scalar(element fr.Element) *bls12377Scalar {
+	s := &bls12377Scalar{element: element}
+	element.BigInt(&s.bigInt)
+	return s
+}
+
+func (s *bls12377Scalar) Bytes() []byte {
+	if s == nil {
+		return nil
+	}
+	b := s.element.Bytes()
+	return b[:]
+}
+
+// generateBLS12377Scalar generates a private key as a random non-zero scalar in
+// the field, reducing 64 bytes read from random.
+func generateBLS12377Scalar(random io.Reader) (*bls12377Scalar, error) {
+	var element fr.Element
+	var wide [64]byte
+	for element.IsZero() {
+		if err := readRandom(random, wide[:]); err != nil {
+			return nil, fmt.Errorf("error generating private key: %w", err)
+		}
+		element.SetBytes(wide[:])
+	}
+	return newBLS12377Scalar(element), nil
+}
+
+// parseBLS12377Scalar parses a canonical big-endian non-zero scalar.
+func parseBLS12377Scalar(b []byte) (*bls12377Scalar, error) {
+	element, err := codec.DecodeBLS12377Scalar(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return newBLS12377Scalar(element), nil
+}
+
+func decodeBLS12377G1(b []byte) (*bls12377.G1Affine, error) {
+	if len(b) != bls12377.SizeOfG1AffineCompressed {
+		return nil, fmt.Errorf("invalid G1 point length %d, expected %d", len(b), bls12377.SizeOfG1AffineCompressed)
+	}
+	p, err := codec.DecodeBLS12377G1(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid G1 point: %w", err)
+	}
+	return &p, nil
+}
+
+func decodeBLS12377G2(b []byte) (*bls12377.G2Affine, error) {
+	if len(b) != bls12377.SizeOfG2AffineCompressed {
+		return nil, fmt.Errorf("invalid G2 point length %d, expected %d", len(b), bls12377.SizeOfG2AffineCompressed)
+	}
+	p, err := codec.DecodeBLS12377G2(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid G2 point: %w", err)
+	}
+	return &p, nil
+}
+
+func bls12377G1Bytes(p *bls12377.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func bls12377G2Bytes(p *bls12377.G2Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+// bls12377Recipient holds keys with the spending key K in G1 and the viewing key V in G2.
+type bls12377Recipient struct {
+	owner string
+	k, v  *bls12377Scalar
+	K     bls12377.G1Affine
+	V     bls12377.G2Affine
+}
+
+func newBLS12377Recipient(owner string, k, v *bls12377Scalar) *bls12377Recipient {
+	r := &bls12377Recipient{owner: owner, k: k, v: v}
+	r.K.ScalarMultiplication(&bls12377G1Gen, &k.bigInt)
+	r.V.ScalarMultiplication(&bls12377G2Gen, &v.bigInt)
+	return r
+}
+
+// newBLS12377ViewOnlyRecipient creates a recipient from the spending public key only.
+func newBLS12377ViewOnlyRecipient(owner string, K *bls12377.G1Affine, v *bls12377Scalar) *bls12377Recipient {
+	r := &bls12377Recipient{owner: owner, v: v, K: *K}
+	r.V.ScalarMultiplication(&bls12377G2Gen, &v.bigInt)
+	return r
+}
+
+func (r *bls12377Recipient) MetaAddress() MetaAddress {
+	return MetaAddress{Spend: bls12377G1Bytes(&r.K), View: bls12377G2Bytes(&r.V)}
+}
+
+func (r *bls12377Recipient) SpendingKey() []byte { return r.k.Bytes() }
+func (r *bls12377Recipient) ViewingKey() []byte  { return r.v.Bytes() }
+
+// ecpdksapBLS12377 is the double-key protocol on BLS12-377, laid out like ecpdksapBN254.
+type ecpdksapBLS12377 struct {
+	timer  *Timer
+	random io.Reader
+}
+
+// ECPDKSAPBLS12377 returns the double-key ECPDKSAP protocol on BLS12-377.
+func ECPDKSAPBLS12377() Protocol { return ecpdksapBLS12377{} }
+
+func (ecpdksapBLS12377) Name() string  { return "ecpdksap" }
+func (ecpdksapBLS12377) Curve() string { return "bls12-377" }
+
+func (p ecpdksapBLS12377) recipient(r Recipient) (*bls12377Recipient, error) {
+	recipient, ok := r.(*bls12377Recipient)
+	if !ok || recipient.owner != ID(p) {
+		return nil, ErrWrongRecipient
+	}
+	return recipient, nil
+}
+
+func (p ecpdksapBLS12377) ephemeral(e Ephemeral) (*bls12377.G2Affine, error) {
+	return ephemeralPoint[bls12377.G2Affine](p, e)
+}
+
+func (p ecpdksapBLS12377) GenerateRecipient() (Recipient, error) {
+	k, err := generateBLS12377Scalar(p.random)
+	if err != nil {
+		return nil, err
+	}
+	v, err := generateBLS12377Scalar(p.random)
+	if err != nil {
+		return nil, err
+	}
+	return newBLS12377Recipient(ID(p), k, v), nil
+}
+
+func (p ecpdksapBLS12377) NewRecipient(spendingKey, viewingKey []byte) (Recipient, error) {
+	k, err := parseBLS12377Scalar(spendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending key: %w", err)
+	}
+	v, err := parseBLS12377Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return newBLS12377Recipient(ID(p), k, v), nil
+}
+
+func (p ecpdksapBLS12377) NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error) {
+	K, err := decodeBLS12377G1(spendingPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending public key: %w", err)
+	}
+	v, err := parseBLS12377Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return newBLS12377ViewOnlyRecipient(ID(p), K, v), nil
+}
+
+// viewTag hashes the compressed shared point to a field element and takes its first byte.
+func (p ecpdksapBLS12377) viewTag(sharedPoint *bls12377.G2Affine) (uint8, error) {
+	start := p.timer.begin()
+	compressedBytes := sharedPoint.Bytes()
+	p.timer.end(Encoding, start)
+	start = p.timer.begin()
+	hashedFieldElements, err := fr.Hash(compressedBytes[:], []byte("view_tag_domain"), 1)
+	p.timer.end(Hashing, start)
+	if err != nil {
+		return 0, fmt.Errorf("error hashing to field: %w", err)
+	}
+	viewTagBytes := hashedFieldElements[0].Bytes()
+	return viewTagBytes[0], nil
+}
+
+// sharedScalar computes e(p, q)^s and hashes it to a field element.
+func (e ecpdksapBLS12377) sharedScalar(p *bls12377.G1Affine, q *bls12377.G2Affine, s *bls12377Scalar) (*bls12377Scalar, error) {
+	start := e.timer.begin()
+	pairingResult, err := bls12377.Pair([]bls12377.G1Affine{*p}, []bls12377.G2Affine{*q})
+	e.timer.end(Pairing, start)
+	if err != nil {
+		return nil, fmt.Errorf("error computing pairing: %w", err)
+	}
+	start = e.timer.begin()
+	var sharedSecret bls12377.GT
+	sharedSecret.CyclotomicExp(pairingResult, &s.bigInt)
+	e.timer.end(Exponentiation, start)
+	start = e.timer.begin()
+	sharedSecretBytes := sharedSecret.Bytes()
+	hashedFieldElements, err := fr.Hash(sharedSecretBytes[:], []byte("view_tag_domain"), 1)
+	e.timer.end(Hashing, start)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing to field: %w", err)
+	}
+	return newBLS12377Scalar(hashedFieldElements[0]), nil
+}
+
+// stealthPublicKey computes P = K + h*G1.
+func (p ecpdksapBLS12377) stealthPublicKey(K *bls12377.G1Affine, h *bls12377Scalar) []byte {
+	start := p.timer.begin()
+	var P bls12377.G1Affine
+	P.ScalarMultiplication(&bls12377G1Gen, &h.bigInt)
+	P.Add(&P, K)
+	p.timer.end(ScalarMultiplication, start)
+	start = p.timer.begin()
+	defer p.timer.end(Encoding, start)
+	return bls12377G1Bytes(&P)
+}
+
+func (p ecpdksapBLS12377) Send(meta MetaAddress) (Announcement, error) {
+	K, err := decodeBLS12377G1(meta.Spend)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("spending public key: %w", err)
+	}
+	V, err := decodeBLS12377G2(meta.View)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("viewing public key: %w", err)
+	}
+	r, err := generateBLS12377Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var R, sharedPoint bls12377.G2Affine
+	R.ScalarMultiplication(&bls12377G2Gen, &r.bigInt)
+	sharedPoint.ScalarMultiplication(V, &r.bigInt)
+	viewTag, err := p.viewTag(&sharedPoint)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	// e(K, V)^r = e(K, R)^v
+	h, err := p.sharedScalar(K, V, r)
+	if err != nil {
+		return Announcement{}, err
+	}
+	return Announcement{
+		Ephemeral: bls12377G2Bytes(&R),
+		ViewTag:   viewTag,
+		Address:   AddressFromPublicKey(p.stealthPublicKey(K, h)),
+	}, nil
+}
+
+func (p ecpdksapBLS12377) RandomAnnouncement() (Announcement, error) {
+	r, err := generateBLS12377Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+	var R bls12377.G2Affine
+	R.ScalarMultiplication(&bls12377G2Gen, &r.bigInt)
+	return randomAnnouncement(p.random, bls12377G2Bytes(&R))
+}
+
+func (p ecpdksapBLS12377) ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return 0, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return 0, err
+	}
+	start := p.timer.begin()
+	var sharedPoint bls12377.G2Affine
+	sharedPoint.ScalarMultiplication(R, &recipient.v.bigInt)
+	p.timer.end(ScalarMultiplication, start)
+	return p.viewTag(&sharedPoint)
+}
+
+func (p ecpdksapBLS12377) receive(r Recipient, ephemeral Ephemeral) (*bls12377Recipient, *bls12377Scalar, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := p.sharedScalar(&recipient.K, R, recipient.v)
+	return recipient, h, err
+}
+
+func (p ecpdksapBLS12377) StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.receive(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return p.stealthPublicKey(&recipient.K, h), nil
+}
+
+func (p ecpdksapBLS12377) Check(r Recipient, ann Decoded) (bool, error) {
+	return check(p, r, ann)
+}
+
+func (p ecpdksapBLS12377) Derive(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.receive(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.k == nil {
+		return nil, ErrViewOnly
+	}
+	var privateKey fr.Element
+	privateKey.Add(&recipient.k.element, &h.element)
+	b := privateKey.Bytes()
+	return b[:], nil
+}
+
+func (ecpdksapBLS12377) PublicKey(privateKey []byte) ([]byte, error) {
+	s, err := parseBLS12377Scalar(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var P bls12377.G1Affine
+	P.ScalarMultiplication(&bls12377G1Gen, &s.bigInt)
+	return bls12377G1Bytes(&P), nil
+}
+
+func (p ecpdksapBLS12377) EncodeEphemeral(ephemeral Ephemeral) []byte {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil
+	}
+	return bls12377G2Bytes(R)
+}
+
+func (p ecpdksapBLS12377) DecodeEphemeral(b []byte) (Ephemeral, error) {
+	R, err := decodeBLS12377G2(b)
+	return decodedEphemeral(p, R, err)
+}
+
+func (p ecpdksapBLS12377) Prepare(ephemeral Ephemeral) (Ephemeral, error) {
+	return preparedEphemeral[bls12377.G2Affine](p, ephemeral)
+}
@@ -0,0 +1,828 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+	"sap-go/codec"
+
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+var bn254G1Gen, bn254G2Gen = func() (bn254.G1Affine, bn254.G2Affine) {
+	_, _, g1Gen, g2Gen := bn254.Generators()
+	return g1Gen, g2Gen
+}()
+
+// bn254Scalar is a private scalar together with the big.Int form used by
+// scalar multiplication and exponentiation.
+type bn254Scalar struct {
+	element fr.Element
+	bigInt  big.Int
+}
+
+func newBN254Scalar(element fr.Element) *bn254Scalar {
+	s := &bn254Scalar{element: element}
+	element.BigInt(&s.bigInt)
+	return s
+}
+
+func (s *bn254Scalar) Bytes() []byte {
+	if s == nil {
+		return nil
+	}
+	b := s.element.Bytes()
+	return b[:]
+}
+
+// generateBN254Scalar generates a private key as a random non-zero scalar in
+// the field, reducing 64 bytes read from random.
+func generateBN254Scalar(random io.Reader) (*bn254Scalar, error) {
+	var element fr.Element
+	var wide [64]byte
+	for element.IsZero() {
+		if err := readRandom(random, wide[:]); err != nil {
+			return nil, fmt.Errorf("error generating private key: %w", err)
+		}
+		element.SetBytes(wide[:])
+	}
+	return newBN254Scalar(element), nil
+}
+
+// parseBN254Scalar parses a canonical big-endian non-zero scalar.
+func parseBN254Scalar(b []byte) (*bn254Scalar, error) {
+	element, err := codec.DecodeBN254Scalar(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return newBN254Scalar(element), nil
+}
+
+// bn254HashToScalar hashes the shared secret to a field element.
+func bn254HashToScalar(t *Timer, sharedSecret *bn254.GT) (*bn254Scalar, error) {
+	start := t.begin()
+	sharedSecretBytes := sharedSecret.Bytes()
+	hashedFieldElements, err := fr.Hash(sharedSecretBytes[:], []byte("view_tag_domain"), 1)
+	t.end(Hashing, start)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing to field: %w", err)
+	}
+	return newBN254Scalar(hashedFieldElements[0]), nil
+}
+
+// bn254PairingExp computes e(p, q)^s.
+func bn254PairingExp(t *Timer, p *bn254.G1Affine, q *bn254.G2Affine, s *bn254Scalar) (bn254.GT, error) {
+	start := t.begin()
+	pairingResult, err := bn254.Pair([]bn254.G1Affine{*p}, []bn254.G2Affine{*q})
+	t.end(Pairing, start)
+	if err != nil {
+		return bn254.GT{}, fmt.Errorf("error computing pairing: %w", err)
+	}
+	start = t.begin()
+	var result bn254.GT
+	result.CyclotomicExp(pairingResult, &s.bigInt)
+	t.end(Exponentiation, start)
+	return result, nil
+}
+
+func decodeBN254G1(b []byte) (*bn254.G1Affine, error) {
+	if len(b) != bn254.SizeOfG1AffineCompressed {
+		return nil, fmt.Errorf("invalid G1 point length %d, expected %d", len(b), bn254.SizeOfG1AffineCompressed)
+	}
+	p, err := codec.DecodeBN254G1(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid G1 point: %w", err)
+	}
+	return &p, nil
+}
+
+func decodeBN254G2(b []byte) (*bn254.G2Affine, error) {
+	if len(b) != bn254.SizeOfG2AffineCompressed {
+		return nil, fmt.Errorf("invalid G2 point length %d, expected %d", len(b), bn254.SizeOfG2AffineCompressed)
+	}
+	p, err := codec.DecodeBN254G2(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid G2 point: %w", err)
+	}
+	return &p, nil
+}
+
+func bn254G1Bytes(p *bn254.G1Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+func bn254G2Bytes(p *bn254.G2Affine) []byte {
+	b := p.Bytes()
+	return b[:]
+}
+
+// bn254G1Recipient holds keys with the spending key K in G1 and the viewing key V in G2.
+type bn254G1Recipient struct {
+	owner string
+	k, v  *bn254Scalar
+	K     bn254.G1Affine
+	V     bn254.G2Affine
+}
+
+func newBN254G1Recipient(owner string, k, v *bn254Scalar) *bn254G1Recipient {
+	r := &bn254G1Recipient{owner: owner, k: k, v: v}
+	r.K.ScalarMultiplication(&bn254G1Gen, &k.bigInt)
+	r.V.ScalarMultiplication(&bn254G2Gen, &v.bigInt)
+	return r
+}
+
+// newBN254G1ViewOnlyRecipient creates a recipient from the spending public key only.
+func newBN254G1ViewOnlyRecipient(owner string, K *bn254.G1Affine, v *bn254Scalar) *bn254G1Recipient {
+	r := &bn254G1Recipient{owner: owner, v: v, K: *K}
+	r.V.ScalarMultiplication(&bn254G2Gen, &v.bigInt)
+	return r
+}
+
+func (r *bn254G1Recipient) MetaAddress() MetaAddress {
+	return MetaAddress{Spend: bn254G1Bytes(&r.K), View: bn254G2Bytes(&r.V)}
+}
+
+func (r *bn254G1Recipient) SpendingKey() []byte { return r.k.Bytes() }
+func (r *bn254G1Recipient) ViewingKey() []byte  { return r.v.Bytes() }
+
+// bn254G2Recipient holds keys with the spending key K in G2 and the viewing key V in G1.
+type bn254G2Recipient struct {
+	owner string
+	k, v  *bn254Scalar
+	K     bn254.G2Affine
+	V     bn254.G1Affine
+}
+
+func newBN254G2Recipient(owner string, k, v *bn254Scalar) *bn254G2Recipient {
+	r := &bn254G2Recipient{owner: owner, k: k, v: v}
+	r.K.ScalarMultiplication(&bn254G2Gen, &k.bigInt)
+	r.V.ScalarMultiplication(&bn254G1Gen, &v.bigInt)
+	return r
+}
+
+// newBN254G2ViewOnlyRecipient creates a recipient from the spending public key only.
+func newBN254G2ViewOnlyRecipient(owner string, K *bn254.G2Affine, v *bn254Scalar) *bn254G2Recipient {
+	r := &bn254G2Recipient{owner: owner, v: v, K: *K}
+	r.V.ScalarMultiplication(&bn254G1Gen, &v.bigInt)
+	return r
+}
+
+func (r *bn254G2Recipient) MetaAddress() MetaAddress {
+	return MetaAddress{Spend: bn254G2Bytes(&r.K), View: bn254G1Bytes(&r.V)}
+}
+
+func (r *bn254G2Recipient) SpendingKey() []byte { return r.k.Bytes() }
+func (r *bn254G2Recipient) ViewingKey() []byte  { return r.v.Bytes() }
+
+// parseBN254Keys restores the scalars of a recipient from their encodings.
+func parseBN254Keys(spendingKey, viewingKey []byte) (k, v *bn254Scalar, err error) {
+	if k, err = parseBN254Scalar(spendingKey); err != nil {
+		return nil, nil, fmt.Errorf("spending key: %w", err)
+	}
+	if v, err = parseBN254Scalar(viewingKey); err != nil {
+		return nil, nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return k, v, nil
+}
+
+func generateBN254Keys(random io.Reader) (k, v *bn254Scalar, err error) {
+	if k, err = generateBN254Scalar(random); err != nil {
+		return nil, nil, err
+	}
+	if v, err = generateBN254Scalar(random); err != nil {
+		return nil, nil, err
+	}
+	return k, v, nil
+}
+
+// bn254StealthG1 computes the stealth public key P = K + h*G1.
+func bn254StealthG1(t *Timer, K *bn254.G1Affine, h *bn254Scalar) []byte {
+	start := t.begin()
+	var P bn254.G1Affine
+	P.ScalarMultiplication(&bn254G1Gen, &h.bigInt)
+	P.Add(&P, K)
+	t.end(ScalarMultiplication, start)
+	start = t.begin()
+	defer t.end(Encoding, start)
+	return bn254G1Bytes(&P)
+}
+
+// bn254StealthG2 computes the stealth public key P = K + h*G2.
+func bn254StealthG2(t *Timer, K *bn254.G2Affine, h *bn254Scalar) []byte {
+	start := t.begin()
+	var P bn254.G2Affine
+	P.ScalarMultiplication(&bn254G2Gen, &h.bigInt)
+	P.Add(&P, K)
+	t.end(ScalarMultiplication, start)
+	start = t.begin()
+	defer t.end(Encoding, start)
+	return bn254G2Bytes(&P)
+}
+
+// bn254StealthPrivateKey computes the stealth private key k + h.
+func bn254StealthPrivateKey(k, h *bn254Scalar) []byte {
+	var p fr.Element
+	p.Add(&k.element, &h.element)
+	b := p.Bytes()
+	return b[:]
+}
+
+// ecpdksapBN254 is the double-key protocol: K in G1, V and R in G2, view tag
+// from the hash of v*R.
+type ecpdksapBN254 struct {
+	timer  *Timer
+	random io.Reader
+}
+
+// ECPDKSAPBN254 returns the double-key ECPDKSAP protocol on BN254.
+func ECPDKSAPBN254() Protocol { return ecpdksapBN254{} }
+
+func (ecpdksapBN254) Name() string  { return "ecpdksap" }
+func (ecpdksapBN254) Curve() string { return "bn254" }
+
+func (p ecpdksapBN254) recipient(r Recipient) (*bn254G1Recipient, error) {
+	recipient, ok := r.(*bn254G1Recipient)
+	if !ok || recipient.owner != ID(p) {
+		return nil, ErrWrongRecipient
+	}
+	return recipient, nil
+}
+
+func (p ecpdksapBN254) ephemeral(e Ephemeral) (*bn254.G2Affine, error) {
+	return ephemeralPoint[bn254.G2Affine](p, e)
+}
+
+func (p ecpdksapBN254) GenerateRecipient() (Recipient, error) {
+	k, v, err := generateBN254Keys(p.random)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G1Recipient(ID(p), k, v), nil
+}
+
+func (p ecpdksapBN254) NewRecipient(spendingKey, viewingKey []byte) (Recipient, error) {
+	k, v, err := parseBN254Keys(spendingKey, viewingKey)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G1Recipient(ID(p), k, v), nil
+}
+
+func (p ecpdksapBN254) NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error) {
+	K, err := decodeBN254G1(spendingPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending public key: %w", err)
+	}
+	v, err := parseBN254Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return newBN254G1ViewOnlyRecipient(ID(p), K, v), nil
+}
+
+// viewTag hashes the compressed shared point to a field element and takes its first byte.
+func (p ecpdksapBN254) viewTag(sharedPoint *bn254.G2Affine) (uint8, error) {
+	start := p.timer.begin()
+	compressedBytes := sharedPoint.Bytes()
+	p.timer.end(Encoding, start)
+	start = p.timer.begin()
+	hashedFieldElements, err := fr.Hash(compressedBytes[:], []byte("view_tag_domain"), 1)
+	p.timer.end(Hashing, start)
+	if err != nil {
+		return 0, fmt.Errorf("error hashing to field: %w", err)
+	}
+	viewTagBytes := hashedFieldElements[0].Bytes()
+	return viewTagBytes[0], nil
+}
+
+func (p ecpdksapBN254) Send(meta MetaAddress) (Announcement, error) {
+	K, err := decodeBN254G1(meta.Spend)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("spending public key: %w", err)
+	}
+	V, err := decodeBN254G2(meta.View)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("viewing public key: %w", err)
+	}
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var R, sharedPoint bn254.G2Affine
+	R.ScalarMultiplication(&bn254G2Gen, &r.bigInt)
+	sharedPoint.ScalarMultiplication(V, &r.bigInt)
+	viewTag, err := p.viewTag(&sharedPoint)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	// e(K, V)^r = e(K, R)^v
+	sharedSecret, err := bn254PairingExp(p.timer, K, V, r)
+	if err != nil {
+		return Announcement{}, err
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	if err != nil {
+		return Announcement{}, err
+	}
+	return Announcement{
+		Ephemeral: bn254G2Bytes(&R),
+		ViewTag:   viewTag,
+		Address:   AddressFromPublicKey(bn254StealthG1(p.timer, K, h)),
+	}, nil
+}
+
+func (p ecpdksapBN254) RandomAnnouncement() (Announcement, error) {
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+	var R bn254.G2Affine
+	R.ScalarMultiplication(&bn254G2Gen, &r.bigInt)
+	return randomAnnouncement(p.random, bn254G2Bytes(&R))
+}
+
+func (p ecpdksapBN254) ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return 0, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return 0, err
+	}
+	// recipient uses the private viewing key and the sender public key
+	start := p.timer.begin()
+	var sharedPoint bn254.G2Affine
+	sharedPoint.ScalarMultiplication(R, &recipient.v.bigInt)
+	p.timer.end(ScalarMultiplication, start)
+	return p.viewTag(&sharedPoint)
+}
+
+func (p ecpdksapBN254) sharedScalar(r Recipient, ephemeral Ephemeral) (*bn254G1Recipient, *bn254Scalar, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharedSecret, err := bn254PairingExp(p.timer, &recipient.K, R, recipient.v)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	return recipient, h, err
+}
+
+func (p ecpdksapBN254) StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return bn254StealthG1(p.timer, &recipient.K, h), nil
+}
+
+func (p ecpdksapBN254) Check(r Recipient, ann Decoded) (bool, error) {
+	return check(p, r, ann)
+}
+
+func (p ecpdksapBN254) Derive(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.k == nil {
+		return nil, ErrViewOnly
+	}
+	return bn254StealthPrivateKey(recipient.k, h), nil
+}
+
+func (ecpdksapBN254) PublicKey(privateKey []byte) ([]byte, error) {
+	s, err := parseBN254Scalar(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var P bn254.G1Affine
+	P.ScalarMultiplication(&bn254G1Gen, &s.bigInt)
+	return bn254G1Bytes(&P), nil
+}
+
+func (p ecpdksapBN254) EncodeEphemeral(ephemeral Ephemeral) []byte {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil
+	}
+	return bn254G2Bytes(R)
+}
+
+func (p ecpdksapBN254) DecodeEphemeral(b []byte) (Ephemeral, error) {
+	R, err := decodeBN254G2(b)
+	return decodedEphemeral(p, R, err)
+}
+
+func (p ecpdksapBN254) Prepare(ephemeral Ephemeral) (Ephemeral, error) {
+	return preparedEphemeral[bn254.G2Affine](p, ephemeral)
+}
+
+// keyChangeBN254 is ECPDKSAP with the keys moved between groups: K in G2, V
+// and R in G1, view tag from the SHA-256 of v*R.
+type keyChangeBN254 struct {
+	timer  *Timer
+	random io.Reader
+}
+
+// KeyChangeBN254 returns the key-change variant of ECPDKSAP on BN254.
+func KeyChangeBN254() Protocol { return keyChangeBN254{} }
+
+func (keyChangeBN254) Name() string  { return "ecpdksap-keychange" }
+func (keyChangeBN254) Curve() string { return "bn254" }
+
+func (p keyChangeBN254) recipient(r Recipient) (*bn254G2Recipient, error) {
+	recipient, ok := r.(*bn254G2Recipient)
+	if !ok || recipient.owner != ID(p) {
+		return nil, ErrWrongRecipient
+	}
+	return recipient, nil
+}
+
+func (p keyChangeBN254) ephemeral(e Ephemeral) (*bn254.G1Affine, error) {
+	return ephemeralPoint[bn254.G1Affine](p, e)
+}
+
+func (p keyChangeBN254) GenerateRecipient() (Recipient, error) {
+	k, v, err := generateBN254Keys(p.random)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G2Recipient(ID(p), k, v), nil
+}
+
+func (p keyChangeBN254) NewRecipient(spendingKey, viewingKey []byte) (Recipient, error) {
+	k, v, err := parseBN254Keys(spendingKey, viewingKey)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G2Recipient(ID(p), k, v), nil
+}
+
+func (p keyChangeBN254) NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error) {
+	K, err := decodeBN254G2(spendingPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending public key: %w", err)
+	}
+	v, err := parseBN254Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return newBN254G2ViewOnlyRecipient(ID(p), K, v), nil
+}
+
+func (p keyChangeBN254) viewTag(sharedPoint *bn254.G1Affine) uint8 {
+	start := p.timer.begin()
+	compressedBytes := sharedPoint.Bytes()
+	p.timer.end(Encoding, start)
+	start = p.timer.begin()
+	hash := sha256.Sum256(compressedBytes[:])
+	p.timer.end(Hashing, start)
+	return hash[0]
+}
+
+func (p keyChangeBN254) Send(meta MetaAddress) (Announcement, error) {
+	K, err := decodeBN254G2(meta.Spend)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("spending public key: %w", err)
+	}
+	V, err := decodeBN254G1(meta.View)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("viewing public key: %w", err)
+	}
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var R, sharedPoint bn254.G1Affine
+	R.ScalarMultiplication(&bn254G1Gen, &r.bigInt)
+	sharedPoint.ScalarMultiplication(V, &r.bigInt)
+
+	// e(V, K)^r = e(R, K)^v
+	sharedSecret, err := bn254PairingExp(p.timer, V, K, r)
+	if err != nil {
+		return Announcement{}, err
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	if err != nil {
+		return Announcement{}, err
+	}
+	return Announcement{
+		Ephemeral: bn254G1Bytes(&R),
+		ViewTag:   p.viewTag(&sharedPoint),
+		Address:   AddressFromPublicKey(bn254StealthG2(p.timer, K, h)),
+	}, nil
+}
+
+func (p keyChangeBN254) RandomAnnouncement() (Announcement, error) {
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+	var R bn254.G1Affine
+	R.ScalarMultiplication(&bn254G1Gen, &r.bigInt)
+	return randomAnnouncement(p.random, bn254G1Bytes(&R))
+}
+
+func (p keyChangeBN254) ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return 0, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return 0, err
+	}
+	start := p.timer.begin()
+	var sharedPoint bn254.G1Affine
+	sharedPoint.ScalarMultiplication(R, &recipient.v.bigInt)
+	p.timer.end(ScalarMultiplication, start)
+	return p.viewTag(&sharedPoint), nil
+}
+
+func (p keyChangeBN254) sharedScalar(r Recipient, ephemeral Ephemeral) (*bn254G2Recipient, *bn254Scalar, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil, nil, err
+	}
+	sharedSecret, err := bn254PairingExp(p.timer, R, &recipient.K, recipient.v)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	return recipient, h, err
+}
+
+func (p keyChangeBN254) StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return bn254StealthG2(p.timer, &recipient.K, h), nil
+}
+
+func (p keyChangeBN254) Check(r Recipient, ann Decoded) (bool, error) {
+	return check(p, r, ann)
+}
+
+func (p keyChangeBN254) Derive(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.k == nil {
+		return nil, ErrViewOnly
+	}
+	return bn254StealthPrivateKey(recipient.k, h), nil
+}
+
+func (keyChangeBN254) PublicKey(privateKey []byte) ([]byte, error) {
+	s, err := parseBN254Scalar(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var P bn254.G2Affine
+	P.ScalarMultiplication(&bn254G2Gen, &s.bigInt)
+	return bn254G2Bytes(&P), nil
+}
+
+func (p keyChangeBN254) EncodeEphemeral(ephemeral Ephemeral) []byte {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil
+	}
+	return bn254G1Bytes(R)
+}
+
+func (p keyChangeBN254) DecodeEphemeral(b []byte) (Ephemeral, error) {
+	R, err := decodeBN254G1(b)
+	return decodedEphemeral(p, R, err)
+}
+
+func (p keyChangeBN254) Prepare(ephemeral Ephemeral) (Ephemeral, error) {
+	return preparedEphemeral[bn254.G1Affine](p, ephemeral)
+}
+
+// singleKeyBN254 is ECPSKSAP: K in G1, V in G2, R in G1. The shared secret
+// e(R, G2)^v feeds both the view tag and the stealth key, so every scanned
+// announcement costs a pairing and an exponentiation.
+type singleKeyBN254 struct {
+	timer  *Timer
+	random io.Reader
+}
+
+// SingleKeyBN254 returns the single-key ECPSKSAP protocol on BN254.
+func SingleKeyBN254() Protocol { return singleKeyBN254{} }
+
+func (singleKeyBN254) Name() string  { return "ecpsksap" }
+func (singleKeyBN254) Curve() string { return "bn254" }
+
+func (p singleKeyBN254) recipient(r Recipient) (*bn254G1Recipient, error) {
+	recipient, ok := r.(*bn254G1Recipient)
+	if !ok || recipient.owner != ID(p) {
+		return nil, ErrWrongRecipient
+	}
+	return recipient, nil
+}
+
+// singleKeyPrepared is an ephemeral key R together with e(R, G2), of which
+// every recipient's shared secret is a power.
+type singleKeyPrepared struct {
+	R       *bn254.G1Affine
+	pairing bn254.GT
+}
+
+func (p singleKeyBN254) ephemeral(e Ephemeral) (*bn254.G1Affine, error) {
+	if prepared, ok := e.(*singleKeyPrepared); ok {
+		return prepared.R, nil
+	}
+	return ephemeralPoint[bn254.G1Affine](p, e)
+}
+
+func (p singleKeyBN254) GenerateRecipient() (Recipient, error) {
+	k, v, err := generateBN254Keys(p.random)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G1Recipient(ID(p), k, v), nil
+}
+
+func (p singleKeyBN254) NewRecipient(spendingKey, viewingKey []byte) (Recipient, error) {
+	k, v, err := parseBN254Keys(spendingKey, viewingKey)
+	if err != nil {
+		return nil, err
+	}
+	return newBN254G1Recipient(ID(p), k, v), nil
+}
+
+func (p singleKeyBN254) NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error) {
+	K, err := decodeBN254G1(spendingPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending public key: %w", err)
+	}
+	v, err := parseBN254Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return newBN254G1ViewOnlyRecipient(ID(p), K, v), nil
+}
+
+func (p singleKeyBN254) Send(meta MetaAddress) (Announcement, error) {
+	K, err := decodeBN254G1(meta.Spend)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("spending public key: %w", err)
+	}
+	V, err := decodeBN254G2(meta.View)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("viewing public key: %w", err)
+	}
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var R bn254.G1Affine
+	R.ScalarMultiplication(&bn254G1Gen, &r.bigInt)
+
+	// e(R, V) = e(R, G2)^v
+	start := p.timer.begin()
+	sharedSecret, err := bn254.Pair([]bn254.G1Affine{R}, []bn254.G2Affine{*V})
+	p.timer.end(Pairing, start)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("error computing pairing: %w", err)
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	if err != nil {
+		return Announcement{}, err
+	}
+	viewTagBytes := h.element.Bytes()
+	return Announcement{
+		Ephemeral: bn254G1Bytes(&R),
+		ViewTag:   viewTagBytes[0],
+		Address:   AddressFromPublicKey(bn254StealthG1(p.timer, K, h)),
+	}, nil
+}
+
+func (p singleKeyBN254) RandomAnnouncement() (Announcement, error) {
+	r, err := generateBN254Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+	var R bn254.G1Affine
+	R.ScalarMultiplication(&bn254G1Gen, &r.bigInt)
+	return randomAnnouncement(p.random, bn254G1Bytes(&R))
+}
+
+func (p singleKeyBN254) sharedScalar(r Recipient, ephemeral Ephemeral) (*bn254G1Recipient, *bn254Scalar, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sharedSecret bn254.GT
+	if prepared, ok := ephemeral.(*singleKeyPrepared); ok {
+		start := p.timer.begin()
+		sharedSecret.CyclotomicExp(prepared.pairing, &recipient.v.bigInt)
+		p.timer.end(Exponentiation, start)
+	} else {
+		R, err := p.ephemeral(ephemeral)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sharedSecret, err = bn254PairingExp(p.timer, R, &bn254G2Gen, recipient.v); err != nil {
+			return nil, nil, err
+		}
+	}
+	h, err := bn254HashToScalar(p.timer, &sharedSecret)
+	return recipient, h, err
+}
+
+func (p singleKeyBN254) ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error) {
+	_, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return 0, err
+	}
+	viewTagBytes := h.element.Bytes()
+	return viewTagBytes[0], nil
+}
+
+func (p singleKeyBN254) StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return bn254StealthG1(p.timer, &recipient.K, h), nil
+}
+
+func (p singleKeyBN254) Check(r Recipient, ann Decoded) (bool, error) {
+	// the view tag and the stealth key share the pairing, compute it once
+	recipient, h, err := p.sharedScalar(r, ann.Ephemeral)
+	if err != nil {
+		return false, err
+	}
+	viewTagBytes := h.element.Bytes()
+	if viewTagBytes[0] != ann.ViewTag {
+		return false, nil
+	}
+	return AddressFromPublicKey(bn254StealthG1(p.timer, &recipient.K, h)) == ann.Address, nil
+}
+
+func (p singleKeyBN254) Derive(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, h, err := p.sharedScalar(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.k == nil {
+		return nil, ErrViewOnly
+	}
+	return bn254StealthPrivateKey(recipient.k, h), nil
+}
+
+func (singleKeyBN254) PublicKey(privateKey []byte) ([]byte, error) {
+	return ecpdksapBN254{}.PublicKey(privateKey)
+}
+
+func (p singleKeyBN254) EncodeEphemeral(ephemeral Ephemeral) []byte {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil
+	}
+	return bn254G1Bytes(R)
+}
+
+func (p singleKeyBN254) DecodeEphemeral(b []byte) (Ephemeral, error) {
+	R, err := decodeBN254G1(b)
+	return decodedEphemeral(p, R, err)
+}
+
+// Prepare computes the pairing e(R, G2) once, leaving one exponentiation per
+// recipient.
+func (p singleKeyBN254) Prepare(ephemeral Ephemeral) (Ephemeral, error) {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	start := p.timer.begin()
+	pairing, err := bn254.Pair([]bn254.G1Affine{*R}, []bn254.G2Affine{bn254G2Gen})
+	p.timer.end(Pairing, start)
+	if err != nil {
+		return nil, fmt.Errorf("error computing pairing: %w", err)
+	}
+	return &singleKeyPrepared{R: R, pairing: pairing}, nil
+}
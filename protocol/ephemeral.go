@@ -0,0 +1,67 @@
+package protocol
+
+import (
+	"fmt"
+	"sap-go/codec"
+)
+
+// EphemeralError reports an ephemeral key that is the identity, off the curve
+// or outside the prime-order subgroup. It matches ErrInvalidEphemeral and the
+// codec error describing the problem.
+type EphemeralError struct {
+	Protocol string
+	Err      error
+}
+
+func (e *EphemeralError) Error() string {
+	return fmt.Sprintf("%s: %v: %v", e.Protocol, ErrInvalidEphemeral, e.Err)
+}
+
+func (e *EphemeralError) Unwrap() []error { return []error{ErrInvalidEphemeral, e.Err} }
+
+// ephemeralKey is an ephemeral point that passed validation when it was
+// decoded, so scanning does not repeat the subgroup check.
+type ephemeralKey[T any] struct{ point *T }
+
+// ephemeralPoint returns the point of an Ephemeral decoded by DecodeEphemeral,
+// or validates a bare point before it is combined with any private key.
+func ephemeralPoint[T any, P interface {
+	*T
+	codec.Point
+}](p Protocol, e Ephemeral) (*T, error) {
+	switch e := e.(type) {
+	case ephemeralKey[T]:
+		return e.point, nil
+	case *T:
+		if e == nil {
+			return nil, ErrWrongEphemeral
+		}
+		if err := codec.Validate(P(e)); err != nil {
+			return nil, &EphemeralError{Protocol: ID(p), Err: err}
+		}
+		return e, nil
+	}
+	return nil, ErrWrongEphemeral
+}
+
+// preparedEphemeral validates e once, for protocols whose scanning has no
+// recipient-independent work beyond that.
+func preparedEphemeral[T any, P interface {
+	*T
+	codec.Point
+}](p Protocol, e Ephemeral) (Ephemeral, error) {
+	point, err := ephemeralPoint[T, P](p, e)
+	if err != nil {
+		return nil, err
+	}
+	return ephemeralKey[T]{point}, nil
+}
+
+// decodedEphemeral wraps a point decoded from an announcement, reporting
+// decoding failures as an EphemeralError.
+func decodedEphemeral[T any](p Protocol, point *T, err error) (Ephemeral, error) {
+	if err != nil {
+		return nil, &EphemeralError{Protocol: ID(p), Err: err}
+	}
+	return ephemeralKey[T]{point}, nil
+}
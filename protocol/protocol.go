@@ -0,0 +1,276 @@
+package protocol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sap-go/codec"
+	"strings"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+)
+
+// AddressLength is the length of a stealth address in bytes.
+const AddressLength = 20
+
+// Address is the account address of a stealth public key.
+type Address [AddressLength]byte
+
+// String returns the 0x prefixed hex representation of the address.
+func (a Address) String() string {
+	return "0x" + hex.EncodeToString(a[:])
+}
+
+func (a Address) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalText parses a hex address with or without the 0x prefix.
+func (a *Address) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "0x"))
+	if err != nil {
+		return fmt.Errorf("invalid address: %w", err)
+	}
+	if len(b) != AddressLength {
+		return fmt.Errorf("invalid address length %d, expected %d", len(b), AddressLength)
+	}
+	copy(a[:], b)
+	return nil
+}
+
+// AddressFromPublicKey hashes an encoded stealth public key into its address.
+func AddressFromPublicKey(publicKey []byte) Address {
+	var address Address
+	hash := sha256.Sum256(publicKey)
+	copy(address[:], hash[:AddressLength])
+	return address
+}
+
+// MetaAddress is published by a recipient so senders can pay them.
+// Both keys are encoded points of the protocol's curve.
+type MetaAddress struct {
+	Spend []byte
+	View  []byte
+}
+
+// Bytes returns the spending public key followed by the viewing public key,
+// the layout of an ERC-5564 stealth meta-address.
+func (m MetaAddress) Bytes() []byte {
+	return append(bytes.Clone(m.Spend), m.View...)
+}
+
+// ParseMetaAddress splits the layout returned by MetaAddress.Bytes into the
+// keys of p. The points are only validated once p decodes them, e.g. in Send.
+func ParseMetaAddress(p Protocol, b []byte) (MetaAddress, error) {
+	spend, view := metaAddressSizes(p)
+	if spend == 0 {
+		return MetaAddress{}, fmt.Errorf("%w: %s", ErrUnknownProtocol, ID(p))
+	}
+	if len(b) != spend+view {
+		return MetaAddress{}, fmt.Errorf("invalid meta address length %d, expected %d", len(b), spend+view)
+	}
+	return MetaAddress{Spend: bytes.Clone(b[:spend]), View: bytes.Clone(b[spend:])}, nil
+}
+
+// metaAddressSizes returns the sizes of the compressed spending and viewing
+// public keys of p.
+func metaAddressSizes(p Protocol) (spend, view int) {
+	switch p.(type) {
+	case dksapSecp256k1:
+		return codec.Secp256k1CompressedSize, codec.Secp256k1CompressedSize
+	case ecpdksapBN254, singleKeyBN254:
+		return bn254.SizeOfG1AffineCompressed, bn254.SizeOfG2AffineCompressed
+	case keyChangeBN254:
+		return bn254.SizeOfG2AffineCompressed, bn254.SizeOfG1AffineCompressed
+	case ecpdksapBLS12377:
+		return bls12377.SizeOfG1AffineCompressed, bls12377.SizeOfG2AffineCompressed
+	}
+	return 0, 0
+}
+
+// Announcement is published by a sender alongside every payment.
+type Announcement struct {
+	Ephemeral []byte
+	ViewTag   uint8
+	Address   Address
+}
+
+// Ephemeral is a decoded ephemeral public key. Its concrete type is private
+// to the Protocol that decoded it.
+type Ephemeral interface{}
+
+// Decoded is an announcement together with its decoded ephemeral public key.
+type Decoded struct {
+	Announcement
+	Ephemeral Ephemeral
+}
+
+// Recipient holds a recipient's keys in the form a Protocol needs to scan.
+type Recipient interface {
+	MetaAddress() MetaAddress
+	// SpendingKey returns the encoded spending private key k, or nil for a
+	// view-only recipient.
+	SpendingKey() []byte
+	// ViewingKey returns the encoded viewing private key v.
+	ViewingKey() []byte
+}
+
+// Protocol is a stealth address protocol on a specific curve.
+type Protocol interface {
+	// Name identifies the protocol, e.g. "ecpdksap".
+	Name() string
+	// Curve identifies the curve the protocol is instantiated on, e.g. "bn254".
+	Curve() string
+
+	// GenerateRecipient creates a recipient with random spending and viewing keys.
+	GenerateRecipient() (Recipient, error)
+	// NewRecipient restores a recipient from encoded spending and viewing keys.
+	NewRecipient(spendingKey, viewingKey []byte) (Recipient, error)
+	// NewViewOnlyRecipient restores a recipient that can scan but not spend
+	// from the encoded spending public key and viewing private key.
+	NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error)
+
+	// Send creates an announcement paying the owner of meta.
+	Send(meta MetaAddress) (Announcement, error)
+	// RandomAnnouncement creates an announcement with a random ephemeral key,
+	// view tag and address, addressed to nobody.
+	RandomAnnouncement() (Announcement, error)
+
+	// ViewTag computes the view tag the recipient expects for ephemeral.
+	ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error)
+	// StealthPublicKey derives the encoded stealth public key for ephemeral.
+	StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error)
+	// Check reports whether ann is addressed to the recipient, filtering by view tag first.
+	Check(r Recipient, ann Decoded) (bool, error)
+	// Derive computes the private key of the stealth address for ephemeral.
+	Derive(r Recipient, ephemeral Ephemeral) ([]byte, error)
+	// PublicKey returns the encoded stealth public key of a derived private key.
+	PublicKey(privateKey []byte) ([]byte, error)
+
+	// EncodeEphemeral returns the compressed encoding of an ephemeral public key.
+	EncodeEphemeral(ephemeral Ephemeral) []byte
+	// DecodeEphemeral parses an ephemeral public key produced by EncodeEphemeral.
+	DecodeEphemeral(b []byte) (Ephemeral, error)
+	// Prepare validates ephemeral and precomputes the part of scanning that
+	// does not depend on the recipient. The result is accepted wherever
+	// ephemeral is, and pays off when it is checked against many recipients.
+	Prepare(ephemeral Ephemeral) (Ephemeral, error)
+}
+
+var (
+	// ErrUnknownProtocol is returned by Lookup for unregistered protocol/curve pairs.
+	ErrUnknownProtocol = errors.New("unknown protocol")
+	// ErrWrongRecipient is returned when a Recipient created by another Protocol is passed in.
+	ErrWrongRecipient = errors.New("recipient belongs to a different protocol")
+	// ErrWrongEphemeral is returned when an Ephemeral decoded by another Protocol is passed in.
+	ErrWrongEphemeral = errors.New("ephemeral key belongs to a different protocol")
+	// ErrViewOnly is returned by Derive for recipients without a spending key.
+	ErrViewOnly = errors.New("view-only recipient cannot derive stealth private keys")
+	// ErrInvalidEphemeral is matched by every EphemeralError.
+	ErrInvalidEphemeral = errors.New("invalid ephemeral key")
+)
+
+var registry = []Protocol{
+	DKSAPSecp256k1(),
+	ECPDKSAPBN254(),
+	ECPDKSAPBLS12377(),
+	KeyChangeBN254(),
+	SingleKeyBN254(),
+}
+
+// All returns every available protocol/curve combination.
+func All() []Protocol {
+	return append([]Protocol(nil), registry...)
+}
+
+// Lookup returns the protocol with the given name on the given curve.
+func Lookup(name, curve string) (Protocol, error) {
+	for _, p := range registry {
+		if p.Name() == name && p.Curve() == curve {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s", ErrUnknownProtocol, name, curve)
+}
+
+// ID returns the protocol/curve label used in reports, e.g. "ecpdksap/bn254".
+func ID(p Protocol) string {
+	return p.Name() + "/" + p.Curve()
+}
+
+// Decode decodes the ephemeral public key of ann.
+func Decode(p Protocol, ann Announcement) (Decoded, error) {
+	ephemeral, err := p.DecodeEphemeral(ann.Ephemeral)
+	if err != nil {
+		return Decoded{}, err
+	}
+	return Decoded{Announcement: ann, Ephemeral: ephemeral}, nil
+}
+
+// check implements Protocol.Check on top of ViewTag and StealthPublicKey.
+func check(p Protocol, r Recipient, ann Decoded) (bool, error) {
+	viewTag, err := p.ViewTag(r, ann.Ephemeral)
+	if err != nil {
+		return false, err
+	}
+	if viewTag != ann.ViewTag {
+		return false, nil
+	}
+	stealthPublicKey, err := p.StealthPublicKey(r, ann.Ephemeral)
+	if err != nil {
+		return false, err
+	}
+	return AddressFromPublicKey(stealthPublicKey) == ann.Address, nil
+}
+
+// randomAnnouncement fills in a view tag and address read from random for an
+// ephemeral key.
+func randomAnnouncement(random io.Reader, ephemeral []byte) (Announcement, error) {
+	var b [1 + AddressLength]byte
+	if err := readRandom(random, b[:]); err != nil {
+		return Announcement{}, fmt.Errorf("error generating random announcement: %w", err)
+	}
+	ann := Announcement{Ephemeral: ephemeral, ViewTag: b[0]}
+	copy(ann.Address[:], b[1:])
+	return ann, nil
+}
+
+// readRandom fills b from random, or from crypto/rand when random is nil.
+func readRandom(random io.Reader, b []byte) error {
+	if random == nil {
+		random = rand.Reader
+	}
+	_, err := io.ReadFull(random, b)
+	return err
+}
+
+// WithRandom returns p drawing its private keys, ephemeral scalars and the
+// view tags and addresses of random announcements from random instead of
+// crypto/rand, so a deterministic random gives reproducible fixtures. p must
+// not be used concurrently unless random is safe for concurrent reads.
+// Protocols that are not registered are returned unchanged.
+func WithRandom(p Protocol, random io.Reader) Protocol {
+	switch p := p.(type) {
+	case dksapSecp256k1:
+		p.random = random
+		return p
+	case ecpdksapBN254:
+		p.random = random
+		return p
+	case keyChangeBN254:
+		p.random = random
+		return p
+	case singleKeyBN254:
+		p.random = random
+		return p
+	case ecpdksapBLS12377:
+		p.random = random
+		return p
+	}
+	return p
+}
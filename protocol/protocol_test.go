@@ -0,0 +1,242 @@
+package protocol
+
+import (
+	"bytes"
+	"errors"
+	"sap-go/internal/codectest"
+	"testing"
+
+	bls12377 "github.com/consensys/gnark-crypto/ecc/bls12-377"
+	bn254 "github.com/consensys/gnark-crypto/ecc/bn254"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+)
+
+// TestProtocols exercises every protocol end to end: a sent announcement is
+// found and its derived key matches the stealth address, a view-only
+// recipient finds it but cannot spend it, announcements for others are not
+// matched, a prepared ephemeral key gives the same results, keys and
+// ephemeral keys survive their encodings and invalid ephemeral keys are
+// rejected before the recipient's keys are used.
+func TestProtocols(t *testing.T) {
+	for _, p := range All() {
+		t.Run(ID(p), func(t *testing.T) { testProtocol(t, p) })
+	}
+}
+
+func testProtocol(t *testing.T, p Protocol) {
+	recipient, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	meta := recipient.MetaAddress()
+
+	restored, err := p.NewRecipient(recipient.SpendingKey(), recipient.ViewingKey())
+	if err != nil {
+		t.Fatalf("NewRecipient: %v", err)
+	}
+	restoredMeta := restored.MetaAddress()
+	if !bytes.Equal(restoredMeta.Spend, meta.Spend) || !bytes.Equal(restoredMeta.View, meta.View) {
+		t.Fatal("NewRecipient: restored recipient has a different meta address")
+	}
+
+	parsed, err := ParseMetaAddress(p, meta.Bytes())
+	if err != nil || !bytes.Equal(parsed.Spend, meta.Spend) || !bytes.Equal(parsed.View, meta.View) {
+		t.Fatalf("ParseMetaAddress: meta address does not round trip (err: %v)", err)
+	}
+	if _, err := ParseMetaAddress(p, meta.Bytes()[1:]); err == nil {
+		t.Fatal("ParseMetaAddress: accepted a truncated meta address")
+	}
+
+	ann, err := p.Send(meta)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	decoded, err := Decode(p, ann)
+	if err != nil {
+		t.Fatalf("DecodeEphemeral: %v", err)
+	}
+	if !bytes.Equal(p.EncodeEphemeral(decoded.Ephemeral), ann.Ephemeral) {
+		t.Fatal("EncodeEphemeral: encoding does not round trip")
+	}
+
+	viewTag, err := p.ViewTag(restored, decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("ViewTag: %v", err)
+	}
+	if viewTag != ann.ViewTag {
+		t.Fatalf("ViewTag: got %d, announced %d", viewTag, ann.ViewTag)
+	}
+	if ok, err := p.Check(restored, decoded); err != nil || !ok {
+		t.Fatalf("Check: announcement for the recipient not matched (err: %v)", err)
+	}
+
+	stealthPublicKey, err := p.StealthPublicKey(restored, decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("StealthPublicKey: %v", err)
+	}
+	privateKey, err := p.Derive(restored, decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	publicKey, err := p.PublicKey(privateKey)
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	if !bytes.Equal(publicKey, stealthPublicKey) {
+		t.Fatal("Derive: private key does not match the stealth public key")
+	}
+	if AddressFromPublicKey(publicKey) != ann.Address {
+		t.Fatal("Derive: private key does not control the announced address")
+	}
+
+	prepared, err := p.Prepare(decoded.Ephemeral)
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	if !bytes.Equal(p.EncodeEphemeral(prepared), ann.Ephemeral) {
+		t.Fatal("Prepare: prepared ephemeral key encodes differently")
+	}
+	if ok, err := p.Check(restored, Decoded{Announcement: ann, Ephemeral: prepared}); err != nil || !ok {
+		t.Fatalf("Check: prepared announcement not matched (err: %v)", err)
+	}
+	if preparedKey, err := p.Derive(restored, prepared); err != nil || !bytes.Equal(preparedKey, privateKey) {
+		t.Fatalf("Derive: prepared ephemeral key gives a different private key (err: %v)", err)
+	}
+
+	viewOnly, err := p.NewViewOnlyRecipient(meta.Spend, recipient.ViewingKey())
+	if err != nil {
+		t.Fatalf("NewViewOnlyRecipient: %v", err)
+	}
+	viewOnlyMeta := viewOnly.MetaAddress()
+	if !bytes.Equal(viewOnlyMeta.Spend, meta.Spend) || !bytes.Equal(viewOnlyMeta.View, meta.View) {
+		t.Fatal("NewViewOnlyRecipient: view-only recipient has a different meta address")
+	}
+	if viewOnly.SpendingKey() != nil {
+		t.Fatal("NewViewOnlyRecipient: view-only recipient holds a spending key")
+	}
+	if ok, err := p.Check(viewOnly, decoded); err != nil || !ok {
+		t.Fatalf("Check: announcement for the view-only recipient not matched (err: %v)", err)
+	}
+	if _, err := p.Derive(viewOnly, decoded.Ephemeral); !errors.Is(err, ErrViewOnly) {
+		t.Fatalf("Derive: view-only recipient: got %v, expected %v", err, ErrViewOnly)
+	}
+
+	other, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	if ok, err := p.Check(other, decoded); err != nil || ok {
+		t.Fatalf("Check: announcement matched another recipient (err: %v)", err)
+	}
+
+	random, err := p.RandomAnnouncement()
+	if err != nil {
+		t.Fatalf("RandomAnnouncement: %v", err)
+	}
+	decodedRandom, err := Decode(p, random)
+	if err != nil {
+		t.Fatalf("DecodeEphemeral: random announcement: %v", err)
+	}
+	if ok, err := p.Check(recipient, decodedRandom); err != nil || ok {
+		t.Fatalf("Check: random announcement matched (err: %v)", err)
+	}
+
+	if _, err := p.DecodeEphemeral(ann.Ephemeral[:len(ann.Ephemeral)-1]); err == nil {
+		t.Fatal("DecodeEphemeral: truncated encoding accepted")
+	}
+	for _, q := range registry {
+		if ID(q) == ID(p) {
+			continue
+		}
+		if _, err := q.ViewTag(recipient, decoded.Ephemeral); !errors.Is(err, ErrWrongRecipient) && !errors.Is(err, ErrWrongEphemeral) {
+			t.Fatalf("ViewTag: %s accepted a recipient of %s", ID(q), ID(p))
+		}
+	}
+	checkInvalidEphemerals(t, p, restored, decoded.Ephemeral)
+}
+
+// invalidEphemeral is a crafted ephemeral key and the codec error it must be rejected with.
+type invalidEphemeral struct {
+	name       string
+	point      Ephemeral
+	compressed []byte
+	err        error
+}
+
+func invalidPoints[T any](invalid []codectest.Invalid[T]) []invalidEphemeral {
+	points := make([]invalidEphemeral, len(invalid))
+	for i := range invalid {
+		inv := invalid[i]
+		points[i] = invalidEphemeral{inv.Name, &inv.Point, inv.Compressed, inv.Err}
+	}
+	return points
+}
+
+// invalidEphemerals returns crafted points in the group of the given ephemeral key.
+func invalidEphemerals(e Ephemeral) []invalidEphemeral {
+	switch e.(type) {
+	case ephemeralKey[bn254.G1Affine]:
+		return invalidPoints(codectest.InvalidBN254G1())
+	case ephemeralKey[bn254.G2Affine]:
+		return invalidPoints(codectest.InvalidBN254G2())
+	case ephemeralKey[bls12377.G2Affine]:
+		return invalidPoints(codectest.InvalidBLS12377G2())
+	case ephemeralKey[secp256k1.G1Affine]:
+		return invalidPoints(codectest.InvalidSecp256k1())
+	}
+	return nil
+}
+
+// checkInvalidEphemeral reports unless err is an EphemeralError caused by want.
+func checkInvalidEphemeral(t *testing.T, op string, err, want error) {
+	t.Helper()
+	var ephemeralErr *EphemeralError
+	if !errors.As(err, &ephemeralErr) || !errors.Is(err, ErrInvalidEphemeral) || !errors.Is(err, want) {
+		t.Errorf("%s: got %v, expected %v", op, err, want)
+	}
+}
+
+// checkInvalidEphemerals hands identity, off-curve and small-subgroup points to
+// every operation that combines an ephemeral key with the recipient's keys.
+func checkInvalidEphemerals(t *testing.T, p Protocol, r Recipient, valid Ephemeral) {
+	t.Helper()
+	invalid := invalidEphemerals(valid)
+	if len(invalid) == 0 {
+		t.Fatalf("no invalid ephemeral keys for %T", valid)
+	}
+	for _, inv := range invalid {
+		_, viewTagErr := p.ViewTag(r, inv.point)
+		_, stealthErr := p.StealthPublicKey(r, inv.point)
+		_, deriveErr := p.Derive(r, inv.point)
+		_, checkErr := p.Check(r, Decoded{Ephemeral: inv.point})
+		_, prepareErr := p.Prepare(inv.point)
+		errs := map[string]error{"ViewTag": viewTagErr, "StealthPublicKey": stealthErr, "Derive": deriveErr, "Check": checkErr, "Prepare": prepareErr}
+		if inv.compressed != nil {
+			_, errs["DecodeEphemeral"] = p.DecodeEphemeral(inv.compressed)
+		}
+		for op, err := range errs {
+			checkInvalidEphemeral(t, op+": "+inv.name, err, inv.err)
+		}
+	}
+}
+
+// TestInstrument checks that an instrumented protocol charges the pairings
+// of Send to its timer, and that DKSAP runs none.
+func TestInstrument(t *testing.T) {
+	for _, p := range All() {
+		r, err := p.GenerateRecipient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var timer Timer
+		if _, err := Instrument(p, &timer).Send(r.MetaAddress()); err != nil {
+			t.Fatalf("%s: Send: %v", ID(p), err)
+		}
+		if pairings := timer.Calls[Pairing]; (pairings > 0) != (p.Name() != "dksap") {
+			t.Errorf("%s: Send timed %d pairings", ID(p), pairings)
+		}
+		if timer.Calls[Hashing] == 0 {
+			t.Errorf("%s: Send timed no hashing", ID(p))
+		}
+	}
+}
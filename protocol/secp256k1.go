@@ -0,0 +1,305 @@
+package protocol
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+	"sap-go/codec"
+
+	"github.com/consensys/gnark-crypto/ecc/secp256k1"
+	"github.com/consensys/gnark-crypto/ecc/secp256k1/fr"
+)
+
+var secp256k1Gen = func() secp256k1.G1Affine {
+	_, g1Gen := secp256k1.Generators()
+	return g1Gen
+}()
+
+// secp256k1Scalar is a private scalar together with its big.Int form.
+type secp256k1Scalar struct {
+	element fr.Element
+	bigInt  big.Int
+}
+
+func newSecp256k1Scalar(element fr.Element) *secp256k1Scalar {
+	s := &secp256k1Scalar{element: element}
+	element.BigInt(&s.bigInt)
+	return s
+}
+
+func (s *secp256k1Scalar) Bytes() []byte {
+	if s == nil {
+		return nil
+	}
+	b := s.element.Bytes()
+	return b[:]
+}
+
+// generateSecp256k1Scalar generates a private key as a random non-zero scalar in
+// the field, reducing 64 bytes read from random.
+func generateSecp256k1Scalar(random io.Reader) (*secp256k1Scalar, error) {
+	var element fr.Element
+	var wide [64]byte
+	for element.IsZero() {
+		if err := readRandom(random, wide[:]); err != nil {
+			return nil, fmt.Errorf("error generating private key: %w", err)
+		}
+		element.SetBytes(wide[:])
+	}
+	return newSecp256k1Scalar(element), nil
+}
+
+// parseSecp256k1Scalar parses a canonical big-endian non-zero scalar.
+func parseSecp256k1Scalar(b []byte) (*secp256k1Scalar, error) {
+	element, err := codec.DecodeSecp256k1Scalar(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	return newSecp256k1Scalar(element), nil
+}
+
+// encodeSecp256k1 returns the SEC1 compressed encoding of p.
+func encodeSecp256k1(p *secp256k1.G1Affine) []byte {
+	return codec.EncodeSecp256k1(p, true)
+}
+
+// decodeSecp256k1 parses a SEC1 compressed point, recovering y from x.
+func decodeSecp256k1(b []byte) (*secp256k1.G1Affine, error) {
+	if len(b) != codec.Secp256k1CompressedSize {
+		return nil, fmt.Errorf("invalid point length %d, expected %d", len(b), codec.Secp256k1CompressedSize)
+	}
+	p, err := codec.DecodeSecp256k1(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid point: %w", err)
+	}
+	return &p, nil
+}
+
+// secp256k1Recipient holds the spending key K and the viewing key V, both in G.
+type secp256k1Recipient struct {
+	owner string
+	k, v  *secp256k1Scalar
+	K, V  secp256k1.G1Affine
+}
+
+func (r *secp256k1Recipient) MetaAddress() MetaAddress {
+	return MetaAddress{Spend: encodeSecp256k1(&r.K), View: encodeSecp256k1(&r.V)}
+}
+
+func (r *secp256k1Recipient) SpendingKey() []byte { return r.k.Bytes() }
+func (r *secp256k1Recipient) ViewingKey() []byte  { return r.v.Bytes() }
+
+// dksapSecp256k1 is DKSAP with view tags as described in BaseSAP, the baseline
+// the pairing based protocols are compared against. S = v*R is hashed with
+// SHA-256; the first byte is the view tag and the hash is the stealth scalar.
+type dksapSecp256k1 struct {
+	timer  *Timer
+	random io.Reader
+}
+
+// DKSAPSecp256k1 returns the DKSAP baseline on secp256k1.
+func DKSAPSecp256k1() Protocol { return dksapSecp256k1{} }
+
+func (dksapSecp256k1) Name() string  { return "dksap" }
+func (dksapSecp256k1) Curve() string { return "secp256k1" }
+
+func (p dksapSecp256k1) newRecipient(k, v *secp256k1Scalar) *secp256k1Recipient {
+	r := &secp256k1Recipient{owner: ID(p), k: k, v: v}
+	r.K.ScalarMultiplicationBase(&k.bigInt)
+	r.V.ScalarMultiplicationBase(&v.bigInt)
+	return r
+}
+
+func (p dksapSecp256k1) recipient(r Recipient) (*secp256k1Recipient, error) {
+	recipient, ok := r.(*secp256k1Recipient)
+	if !ok || recipient.owner != ID(p) {
+		return nil, ErrWrongRecipient
+	}
+	return recipient, nil
+}
+
+func (p dksapSecp256k1) ephemeral(e Ephemeral) (*secp256k1.G1Affine, error) {
+	return ephemeralPoint[secp256k1.G1Affine](p, e)
+}
+
+func (p dksapSecp256k1) GenerateRecipient() (Recipient, error) {
+	k, err := generateSecp256k1Scalar(p.random)
+	if err != nil {
+		return nil, err
+	}
+	v, err := generateSecp256k1Scalar(p.random)
+	if err != nil {
+		return nil, err
+	}
+	return p.newRecipient(k, v), nil
+}
+
+func (p dksapSecp256k1) NewRecipient(spendingKey, viewingKey []byte) (Recipient, error) {
+	k, err := parseSecp256k1Scalar(spendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending key: %w", err)
+	}
+	v, err := parseSecp256k1Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	return p.newRecipient(k, v), nil
+}
+
+func (p dksapSecp256k1) NewViewOnlyRecipient(spendingPublicKey, viewingKey []byte) (Recipient, error) {
+	K, err := decodeSecp256k1(spendingPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("spending public key: %w", err)
+	}
+	v, err := parseSecp256k1Scalar(viewingKey)
+	if err != nil {
+		return nil, fmt.Errorf("viewing key: %w", err)
+	}
+	r := &secp256k1Recipient{owner: ID(p), v: v, K: *K}
+	r.V.ScalarMultiplicationBase(&v.bigInt)
+	return r, nil
+}
+
+// hashSharedSecret computes S = s*P and hashes it with SHA-256.
+func (p dksapSecp256k1) hashSharedSecret(s *secp256k1Scalar, P *secp256k1.G1Affine) [sha256.Size]byte {
+	start := p.timer.begin()
+	var sharedSecret secp256k1.G1Affine
+	sharedSecret.ScalarMultiplication(P, &s.bigInt)
+	p.timer.end(ScalarMultiplication, start)
+	start = p.timer.begin()
+	defer p.timer.end(Hashing, start)
+	sharedSecretBytes := sharedSecret.RawBytes()
+	return sha256.Sum256(sharedSecretBytes[:])
+}
+
+// stealthPublicKey computes P = K + hash(S)*G.
+func (p dksapSecp256k1) stealthPublicKey(K *secp256k1.G1Affine, hashedSharedSecret [sha256.Size]byte) []byte {
+	start := p.timer.begin()
+	var h fr.Element
+	h.SetBytes(hashedSharedSecret[:])
+	hBigInt := new(big.Int)
+	h.BigInt(hBigInt)
+
+	var P secp256k1.G1Affine
+	P.ScalarMultiplicationBase(hBigInt)
+	P.Add(&P, K)
+	p.timer.end(ScalarMultiplication, start)
+	start = p.timer.begin()
+	defer p.timer.end(Encoding, start)
+	return encodeSecp256k1(&P)
+}
+
+func (p dksapSecp256k1) Send(meta MetaAddress) (Announcement, error) {
+	K, err := decodeSecp256k1(meta.Spend)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("spending public key: %w", err)
+	}
+	V, err := decodeSecp256k1(meta.View)
+	if err != nil {
+		return Announcement{}, fmt.Errorf("viewing public key: %w", err)
+	}
+	r, err := generateSecp256k1Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+
+	var R secp256k1.G1Affine
+	R.ScalarMultiplicationBase(&r.bigInt)
+	// sender side: S = r*V
+	hashedSharedSecret := p.hashSharedSecret(r, V)
+	return Announcement{
+		Ephemeral: encodeSecp256k1(&R),
+		ViewTag:   hashedSharedSecret[0],
+		Address:   AddressFromPublicKey(p.stealthPublicKey(K, hashedSharedSecret)),
+	}, nil
+}
+
+func (p dksapSecp256k1) RandomAnnouncement() (Announcement, error) {
+	r, err := generateSecp256k1Scalar(p.random)
+	if err != nil {
+		return Announcement{}, err
+	}
+	var R secp256k1.G1Affine
+	R.ScalarMultiplicationBase(&r.bigInt)
+	return randomAnnouncement(p.random, encodeSecp256k1(&R))
+}
+
+func (p dksapSecp256k1) receive(r Recipient, ephemeral Ephemeral) (*secp256k1Recipient, [sha256.Size]byte, error) {
+	recipient, err := p.recipient(r)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, err
+	}
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, err
+	}
+	return recipient, p.hashSharedSecret(recipient.v, R), nil
+}
+
+func (p dksapSecp256k1) ViewTag(r Recipient, ephemeral Ephemeral) (uint8, error) {
+	_, hashedSharedSecret, err := p.receive(r, ephemeral)
+	return hashedSharedSecret[0], err
+}
+
+func (p dksapSecp256k1) StealthPublicKey(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, hashedSharedSecret, err := p.receive(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	return p.stealthPublicKey(&recipient.K, hashedSharedSecret), nil
+}
+
+func (p dksapSecp256k1) Check(r Recipient, ann Decoded) (bool, error) {
+	// the view tag and the stealth key share S, compute it once
+	recipient, hashedSharedSecret, err := p.receive(r, ann.Ephemeral)
+	if err != nil {
+		return false, err
+	}
+	if hashedSharedSecret[0] != ann.ViewTag {
+		return false, nil
+	}
+	return AddressFromPublicKey(p.stealthPublicKey(&recipient.K, hashedSharedSecret)) == ann.Address, nil
+}
+
+func (p dksapSecp256k1) Derive(r Recipient, ephemeral Ephemeral) ([]byte, error) {
+	recipient, hashedSharedSecret, err := p.receive(r, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if recipient.k == nil {
+		return nil, ErrViewOnly
+	}
+	var privateKey fr.Element
+	privateKey.SetBytes(hashedSharedSecret[:])
+	privateKey.Add(&privateKey, &recipient.k.element)
+	b := privateKey.Bytes()
+	return b[:], nil
+}
+
+func (dksapSecp256k1) PublicKey(privateKey []byte) ([]byte, error) {
+	s, err := parseSecp256k1Scalar(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	var P secp256k1.G1Affine
+	P.ScalarMultiplicationBase(&s.bigInt)
+	return encodeSecp256k1(&P), nil
+}
+
+func (p dksapSecp256k1) EncodeEphemeral(ephemeral Ephemeral) []byte {
+	R, err := p.ephemeral(ephemeral)
+	if err != nil {
+		return nil
+	}
+	return encodeSecp256k1(R)
+}
+
+func (p dksapSecp256k1) DecodeEphemeral(b []byte) (Ephemeral, error) {
+	R, err := decodeSecp256k1(b)
+	return decodedEphemeral(p, R, err)
+}
+
+func (p dksapSecp256k1) Prepare(ephemeral Ephemeral) (Ephemeral, error) {
+	return preparedEphemeral[secp256k1.G1Affine](p, ephemeral)
+}
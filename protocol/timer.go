@@ -0,0 +1,84 @@
+package protocol
+
+import (
+	"strconv"
+	"time"
+)
+
+// Primitive is a cryptographic operation a protocol is built from.
+type Primitive int
+
+const (
+	// Pairing computes a bilinear pairing.
+	Pairing Primitive = iota
+	// Exponentiation raises a pairing result to a scalar.
+	Exponentiation
+	// ScalarMultiplication multiplies a curve point by a scalar, including
+	// the point addition of the stealth public key.
+	ScalarMultiplication
+	// Hashing hashes a shared secret to a scalar or a view tag.
+	Hashing
+	// Encoding compresses a curve point, such as the stealth public key the
+	// address is taken from.
+	Encoding
+	numPrimitives
+)
+
+// Primitives lists every primitive.
+var Primitives = []Primitive{Pairing, Exponentiation, ScalarMultiplication, Hashing, Encoding}
+
+var primitiveNames = [numPrimitives]string{"pairing", "exponentiation", "scalar multiplication", "hashing", "encoding"}
+
+func (p Primitive) String() string {
+	if p < 0 || p >= numPrimitives {
+		return "primitive(" + strconv.Itoa(int(p)) + ")"
+	}
+	return primitiveNames[p]
+}
+
+// Timer accumulates the time an instrumented protocol spends in each
+// primitive. It is not safe for concurrent use.
+type Timer struct {
+	Durations [numPrimitives]time.Duration
+	Calls     [numPrimitives]int
+}
+
+// begin returns the start of a primitive, or the zero time without a timer.
+func (t *Timer) begin() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// end charges the time elapsed since start to primitive.
+func (t *Timer) end(primitive Primitive, start time.Time) {
+	if t == nil {
+		return
+	}
+	t.Durations[primitive] += time.Since(start)
+	t.Calls[primitive]++
+}
+
+// Instrument returns p with the primitives it runs timed by t. Protocols
+// that are not registered are returned unchanged.
+func Instrument(p Protocol, t *Timer) Protocol {
+	switch p := p.(type) {
+	case dksapSecp256k1:
+		p.timer = t
+		return p
+	case ecpdksapBN254:
+		p.timer = t
+		return p
+	case keyChangeBN254:
+		p.timer = t
+		return p
+	case singleKeyBN254:
+		p.timer = t
+		return p
+	case ecpdksapBLS12377:
+		p.timer = t
+		return p
+	}
+	return p
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sap-go/bench"
+	"sap-go/chart"
+	"sap-go/dataset"
+	"sap-go/harness"
+	"sap-go/protocol"
+	"sap-go/results"
+	"strconv"
+	"strings"
+)
+
+func parseCounts(value string) ([]int, error) {
+	var counts []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid announcement count %q", field)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
+// loadDataset returns the dataset for p with n announcements from dir,
+// generating and saving it on first use. Without dir a fresh dataset is generated.
+func loadDataset(p protocol.Protocol, opts *bench.Options, dir string) (*dataset.Dataset, error) {
+	if dir == "" {
+		return harness.GenerateDataset(p, opts)
+	}
+	path := filepath.Join(dir, dataset.FileName(p.Name(), p.Curve(), opts.Announcements))
+	d, err := dataset.Load(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return d, err
+	}
+	if d, err = harness.GenerateDataset(p, opts); err != nil {
+		return nil, err
+	}
+	if err := dataset.Save(path, d); err != nil {
+		return nil, err
+	}
+	fmt.Println("Dataset saved to", path)
+	return d, nil
+}
+
+// runMatrix runs the experiment and stage breakdown of every protocol for
+// each announcement count, writing the CSV files to outDir.
+func runMatrix(counts []int, runs, owned int, outDir, datasetDir string, profiles bench.Profiles) error {
+	for _, p := range protocol.All() {
+		for _, n := range counts {
+			fmt.Printf("Running %s with %d announcements\n", protocol.ID(p), n)
+			opts := &bench.Options{Announcements: n, Owned: owned, Runs: runs, Out: outDir, Profiles: profiles}
+			d, err := loadDataset(p, opts, datasetDir)
+			if err != nil {
+				return fmt.Errorf("error preparing dataset for %s: %w", protocol.ID(p), err)
+			}
+			fixture, err := harness.FixtureFromDataset(p, d)
+			if err != nil {
+				return fmt.Errorf("error decoding dataset for %s: %w", protocol.ID(p), err)
+			}
+			if err := harness.RunExperiment(p, fixture, opts); err != nil {
+				return fmt.Errorf("error running %s: %w", protocol.ID(p), err)
+			}
+			if err := harness.RunStageBreakdown(p, fixture, opts); err != nil {
+				return fmt.Errorf("error running %s: %w", protocol.ID(p), err)
+			}
+		}
+	}
+	return nil
+}
+
+func loadBreakdowns(dir string) ([]bench.Breakdown, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "stage_breakdown_*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	var breakdowns []bench.Breakdown
+	for _, path := range paths {
+		fileBreakdowns, err := bench.LoadBreakdowns(path)
+		if err != nil {
+			return nil, err
+		}
+		breakdowns = append(breakdowns, fileBreakdowns...)
+	}
+	return breakdowns, nil
+}
+
+func main() {
+	countsFlag := flag.String("n", "5000,10000,20000,40000,80000", "comma separated announcement counts")
+	runs := flag.Int("runs", 10, "number of runs per configuration")
+	owned := flag.Int("owned", 1, "number of announcements addressed to the recipient")
+	outDir := flag.String("out", "comparison", "directory for result files, charts and the report")
+	skipRun := flag.Bool("skip-run", false, "only render the report from result files already in -out")
+	var profiles bench.Profiles
+	flag.Var(&profiles, "profile", "comma separated profiles to write per configuration: cpu, heap, allocs, trace")
+	datasetDir := flag.String("datasets", "", "directory to reuse dataset files from, missing ones are generated and saved there")
+	flag.Parse()
+
+	counts, err := parseCounts(*countsFlag)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	for _, dir := range []string{*outDir, *datasetDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			fmt.Println("Error creating output directory:", err)
+			os.Exit(1)
+		}
+	}
+
+	if !*skipRun {
+		if err := runMatrix(counts, *runs, *owned, *outDir, *datasetDir, profiles); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	records, err := results.LoadDir(*outDir, "", "")
+	if err != nil {
+		fmt.Println("Error loading experiment results:", err)
+		os.Exit(1)
+	}
+	breakdowns, err := loadBreakdowns(*outDir)
+	if err != nil {
+		fmt.Println("Error loading stage breakdowns:", err)
+		os.Exit(1)
+	}
+
+	summaries := results.Summarize(records)
+	if _, err := chart.WriteResultCharts(summaries, *outDir); err != nil {
+		fmt.Println("Error writing charts:", err)
+		os.Exit(1)
+	}
+
+	reportPath := filepath.Join(*outDir, "REPORT.md")
+	file, err := os.Create(reportPath)
+	if err != nil {
+		fmt.Println("Error creating report:", err)
+		os.Exit(1)
+	}
+	err = writeMarkdown(file, summaries, breakdowns, reportOptions{runs: *runs, owned: *owned})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Println("Error writing report:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Report saved to", reportPath)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sap-go/bench"
+	"sap-go/chart"
+	"sap-go/protocol"
+	"sap-go/results"
+	"time"
+)
+
+// baselineSeries is the configuration speedups are reported against.
+const baselineSeries = "dksap/secp256k1"
+
+// reportOptions describes how the measurements in a report were taken.
+type reportOptions struct {
+	runs  int
+	owned int
+}
+
+// writeMarkdown renders the comparison report for the given results.
+func writeMarkdown(w io.Writer, summaries []results.Summary, breakdowns []bench.Breakdown, opts reportOptions) error {
+	baseline := make(map[int]float64)
+	for _, s := range summaries {
+		if s.Series() == baselineSeries {
+			baseline[s.PublicKeys] = s.Mean
+		}
+	}
+	_, publicKeys := results.Series(summaries)
+
+	var err error
+	printf := func(format string, args ...interface{}) {
+		if err == nil {
+			_, err = fmt.Fprintf(w, format, args...)
+		}
+	}
+
+	printf("# Stealth Address Protocol Comparison\n\n")
+	printf("Generated %s with %s on %s/%s (%d CPUs).\n", time.Now().Format(time.RFC1123), runtime.Version(), runtime.GOOS, runtime.GOARCH, runtime.NumCPU())
+	printf("Each configuration scans the full announcement set with view tags, %d run(s) per configuration and %d owned announcement(s).\n\n", opts.runs, opts.owned)
+	printf("![Performance comparison](%s)\n\n", chart.BarChartFile)
+	printf("![Scan time scaling](%s)\n\n", chart.LineChartFile)
+
+	printf("## Scan Time\n\n")
+	printf("Speedup is the mean scan time of DKSAP with view tag (`%s`) divided by the mean scan time of the configuration.\n\n", baselineSeries)
+	for _, n := range publicKeys {
+		printf("### %d Announcements\n\n", n)
+		printf("| Protocol | Curve | Mean (ms) | Std dev (ms) | Runs | Allocs per announcement | Speedup vs DKSAP with view tag |\n")
+		printf("|---|---|---:|---:|---:|---:|---:|\n")
+		for _, s := range summaries {
+			if s.PublicKeys != n {
+				continue
+			}
+			speedup := "n/a"
+			if base, ok := baseline[n]; ok && s.Mean > 0 {
+				speedup = fmt.Sprintf("%.2fx", base/s.Mean)
+			}
+			printf("| %s | %s | %.2f | %.2f | %d | %.1f | %s |\n", s.Protocol, s.Curve, s.Mean, s.StdDev, s.Runs, s.AllocsPerAnnouncement, speedup)
+		}
+		printf("\n")
+	}
+
+	if len(breakdowns) > 0 {
+		printf("## Stage Breakdown\n\n")
+		printf("Share of the view tag scan time spent in each stage.\n\n")
+		printf("| Protocol | Curve | Announcements |")
+		for _, stage := range bench.Stages {
+			printf(" %s |", stage)
+		}
+		printf(" other | Total (ms) |\n")
+		printf("|---|---|---:|")
+		for range bench.Stages {
+			printf("---:|")
+		}
+		printf("---:|---:|\n")
+		for i := range breakdowns {
+			b := &breakdowns[i]
+			printf("| %s | %s | %d |", b.Protocol, b.Curve, b.Announcements)
+			for _, stage := range bench.Stages {
+				printf(" %.1f%% |", b.Share(stage))
+			}
+			printf(" %.1f%% | %.2f |\n", 100-sumShares(b), float64(b.Total.Microseconds())/1000)
+		}
+		printf("\n")
+
+		printf("## Primitive Breakdown\n\n")
+		printf("Share of the view tag scan time spent in each primitive, across all stages.\n\n")
+		printf("| Protocol | Curve | Announcements |")
+		for _, primitive := range protocol.Primitives {
+			printf(" %s |", primitive)
+		}
+		printf("\n|---|---|---:|")
+		for range protocol.Primitives {
+			printf("---:|")
+		}
+		printf("\n")
+		for i := range breakdowns {
+			b := &breakdowns[i]
+			printf("| %s | %s | %d |", b.Protocol, b.Curve, b.Announcements)
+			for _, primitive := range protocol.Primitives {
+				printf(" %.1f%% |", b.PrimitiveShare(primitive.String()))
+			}
+			printf("\n")
+		}
+		printf("\n")
+	}
+	return err
+}
+
+func sumShares(b *bench.Breakdown) float64 {
+	var sum float64
+	for _, stage := range bench.Stages {
+		sum += b.Share(stage)
+	}
+	return sum
+}
@@ -0,0 +1,66 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// BatchSummary compares scanning for a number of recipients in one pass with
+// scanning once per recipient. Times are means in milliseconds.
+type BatchSummary struct {
+	Key
+	Recipients int
+	Runs       int
+	Batch      float64
+	Separate   float64
+	// PerRecipient is Batch divided by Recipients.
+	PerRecipient float64
+	// Saving is Separate divided by Batch.
+	Saving float64
+}
+
+// BatchFileName returns the canonical CSV file name for a batch scanning experiment.
+func BatchFileName(publicKeys int) string {
+	return fmt.Sprintf("batch_results_%d_public_keys.csv", publicKeys)
+}
+
+// BatchScaling summarizes the batch and separate scan times of key for one
+// number of recipients.
+func BatchScaling(key Key, recipients int, batch, separate []float64) BatchSummary {
+	s := BatchSummary{Key: key, Recipients: recipients, Runs: len(batch), Batch: Mean(batch), Separate: Mean(separate)}
+	if recipients > 0 {
+		s.PerRecipient = s.Batch / float64(recipients)
+	}
+	if s.Batch > 0 {
+		s.Saving = s.Separate / s.Batch
+	}
+	return s
+}
+
+// WriteBatch writes batch scanning summaries as CSV.
+func WriteBatch(w io.Writer, summaries []BatchSummary) error {
+	rows := [][]string{{ColumnProtocol, ColumnCurve, ColumnPublicKeys, "Recipients", "Runs", "Batch (ms)", "Separate (ms)", "Per recipient (ms)", "Saving"}}
+	for _, s := range summaries {
+		rows = append(rows, []string{
+			s.Protocol, s.Curve, strconv.Itoa(s.PublicKeys), strconv.Itoa(s.Recipients), strconv.Itoa(s.Runs),
+			fmt.Sprintf("%.2f", s.Batch), fmt.Sprintf("%.2f", s.Separate), fmt.Sprintf("%.2f", s.PerRecipient), fmt.Sprintf("%.2f", s.Saving),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// SaveBatch writes batch scanning summaries to the CSV file at path.
+func SaveBatch(path string, summaries []BatchSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBatch(file, summaries); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,76 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// CoreSummary is the scan time of one configuration at one GOMAXPROCS setting.
+type CoreSummary struct {
+	Key
+	Procs  int
+	Runs   int
+	Mean   float64
+	StdDev float64
+	// Speedup is the mean scan time at one proc divided by Mean.
+	Speedup float64
+	// Efficiency is Speedup divided by Procs.
+	Efficiency float64
+}
+
+// MulticoreFileName returns the canonical CSV file name for a multi-core scaling experiment.
+func MulticoreFileName(publicKeys int) string {
+	return fmt.Sprintf("multicore_results_%d_public_keys.csv", publicKeys)
+}
+
+// CoreScaling summarizes the scan times in milliseconds of key per GOMAXPROCS
+// setting. Speedups are relative to the single proc run, so durations must
+// contain an entry for 1.
+func CoreScaling(key Key, durations map[int][]float64) ([]CoreSummary, error) {
+	serial, ok := durations[1]
+	if !ok || len(serial) == 0 {
+		return nil, fmt.Errorf("%s: no single proc measurement", key.Series())
+	}
+	serialMean := Mean(serial)
+
+	summaries := make([]CoreSummary, 0, len(durations))
+	for procs, runs := range durations {
+		s := CoreSummary{Key: key, Procs: procs, Runs: len(runs), Mean: Mean(runs), StdDev: StdDev(runs)}
+		if s.Mean > 0 {
+			s.Speedup = serialMean / s.Mean
+			s.Efficiency = s.Speedup / float64(procs)
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Procs < summaries[j].Procs })
+	return summaries, nil
+}
+
+// WriteCores writes multi-core summaries as CSV.
+func WriteCores(w io.Writer, summaries []CoreSummary) error {
+	rows := [][]string{{ColumnProtocol, ColumnCurve, ColumnPublicKeys, "GOMAXPROCS", "Runs", "Mean (ms)", "Std dev (ms)", "Speedup", "Efficiency"}}
+	for _, s := range summaries {
+		rows = append(rows, []string{
+			s.Protocol, s.Curve, strconv.Itoa(s.PublicKeys), strconv.Itoa(s.Procs), strconv.Itoa(s.Runs),
+			fmt.Sprintf("%.2f", s.Mean), fmt.Sprintf("%.2f", s.StdDev), fmt.Sprintf("%.2f", s.Speedup), fmt.Sprintf("%.2f", s.Efficiency),
+		})
+	}
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// SaveCores writes multi-core summaries to the CSV file at path.
+func SaveCores(path string, summaries []CoreSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteCores(file, summaries); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,253 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Column names used in experiment CSV files.
+const (
+	ColumnProtocol   = "Protocol"
+	ColumnCurve      = "Curve"
+	ColumnRun        = "Run"
+	ColumnDuration   = "Duration (ms)"
+	ColumnPublicKeys = "Public Keys"
+	ColumnAllocs     = "Allocs per announcement"
+)
+
+// Record is a single measured run of an experiment.
+type Record struct {
+	Protocol   string
+	Curve      string
+	Run        int
+	DurationMs float64
+	PublicKeys int
+	// AllocsPerAnnouncement is the number of heap allocations per scanned announcement.
+	AllocsPerAnnouncement float64
+}
+
+// Key identifies one experiment configuration.
+type Key struct {
+	Protocol   string
+	Curve      string
+	PublicKeys int
+}
+
+// Series returns the protocol/curve label used to group configurations in charts and tables.
+func (k Key) Series() string {
+	return k.Protocol + "/" + k.Curve
+}
+
+// Key returns the configuration the record belongs to.
+func (r Record) Key() Key {
+	return Key{Protocol: r.Protocol, Curve: r.Curve, PublicKeys: r.PublicKeys}
+}
+
+// FileName returns the canonical CSV file name for an experiment configuration.
+func FileName(protocol, curve string, publicKeys int) string {
+	return fmt.Sprintf("experiment_results_%s_%s_%d_public_keys.csv", protocol, curve, publicKeys)
+}
+
+// Read parses experiment records from r. Files written before the Protocol and
+// Curve columns existed are accepted; their records take protocol and curve
+// from the given defaults. The trailing "Average" row is skipped since it can
+// be recomputed from the runs. The allocation column is optional.
+func Read(r io.Reader, protocol, curve string) ([]Record, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{ColumnRun, ColumnDuration, ColumnPublicKeys} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	records := make([]Record, 0, len(rows)-1)
+	for line, row := range rows[1:] {
+		if row[columns[ColumnRun]] == "Average" {
+			continue
+		}
+		record := Record{Protocol: protocol, Curve: curve}
+		if i, ok := columns[ColumnProtocol]; ok {
+			record.Protocol = row[i]
+		}
+		if i, ok := columns[ColumnCurve]; ok {
+			record.Curve = row[i]
+		}
+		if record.Run, err = strconv.Atoi(row[columns[ColumnRun]]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid run: %w", line+2, err)
+		}
+		if record.DurationMs, err = strconv.ParseFloat(row[columns[ColumnDuration]], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid duration: %w", line+2, err)
+		}
+		if record.PublicKeys, err = strconv.Atoi(row[columns[ColumnPublicKeys]]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid public key count: %w", line+2, err)
+		}
+		if i, ok := columns[ColumnAllocs]; ok {
+			if record.AllocsPerAnnouncement, err = strconv.ParseFloat(row[i], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid allocation count: %w", line+2, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Load reads the records of a single experiment CSV file.
+func Load(path, protocol, curve string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := Read(file, protocol, curve)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return records, nil
+}
+
+// LoadDir reads every experiment_results_*.csv file in dir.
+func LoadDir(dir, protocol, curve string) ([]Record, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "experiment_results_*.csv"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no experiment results found in %s", dir)
+	}
+
+	var records []Record
+	for _, path := range paths {
+		fileRecords, err := Load(path, protocol, curve)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	return records, nil
+}
+
+// LoadPath reads records from a single CSV file or from every result file in a directory.
+func LoadPath(path, protocol, curve string) ([]Record, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadDir(path, protocol, curve)
+	}
+	return Load(path, protocol, curve)
+}
+
+// Write writes records as CSV followed by an "Average" row per configuration.
+func Write(w io.Writer, records []Record) error {
+	rows := make([][]string, 0, len(records)+2)
+	rows = append(rows, []string{ColumnProtocol, ColumnCurve, ColumnRun, ColumnDuration, ColumnPublicKeys, ColumnAllocs})
+	for _, r := range records {
+		rows = append(rows, []string{r.Protocol, r.Curve, strconv.Itoa(r.Run), fmt.Sprintf("%.2f", r.DurationMs), strconv.Itoa(r.PublicKeys),
+			fmt.Sprintf("%.2f", r.AllocsPerAnnouncement)})
+	}
+	for _, s := range Summarize(records) {
+		rows = append(rows, []string{s.Protocol, s.Curve, "Average", fmt.Sprintf("%.2f", s.Mean), strconv.Itoa(s.PublicKeys),
+			fmt.Sprintf("%.2f", s.AllocsPerAnnouncement)})
+	}
+
+	writer := csv.NewWriter(w)
+	return writer.WriteAll(rows)
+}
+
+// Save writes records to the CSV file at path.
+func Save(path string, records []Record) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := Write(file, records); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Summary aggregates all runs of one configuration.
+type Summary struct {
+	Key
+	Runs      int
+	Mean      float64
+	StdDev    float64
+	Durations []float64
+	// AllocsPerAnnouncement is the mean allocation count per scanned announcement.
+	AllocsPerAnnouncement float64
+}
+
+// Summarize groups records by configuration, ordered by protocol, curve and
+// number of public keys.
+func Summarize(records []Record) []Summary {
+	groups := make(map[Key][]float64)
+	allocs := make(map[Key][]float64)
+	for _, r := range records {
+		groups[r.Key()] = append(groups[r.Key()], r.DurationMs)
+		allocs[r.Key()] = append(allocs[r.Key()], r.AllocsPerAnnouncement)
+	}
+
+	summaries := make([]Summary, 0, len(groups))
+	for key, durations := range groups {
+		summaries = append(summaries, Summary{
+			Key:       key,
+			Runs:      len(durations),
+			Mean:      Mean(durations),
+			StdDev:    StdDev(durations),
+			Durations: durations,
+
+			AllocsPerAnnouncement: Mean(allocs[key]),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i].Key, summaries[j].Key
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		if a.Curve != b.Curve {
+			return a.Curve < b.Curve
+		}
+		return a.PublicKeys < b.PublicKeys
+	})
+	return summaries
+}
+
+// Series returns the distinct protocol/curve labels and announcement counts
+// present in summaries, both sorted.
+func Series(summaries []Summary) (series []string, publicKeys []int) {
+	seenSeries := make(map[string]bool)
+	seenKeys := make(map[int]bool)
+	for _, s := range summaries {
+		if !seenSeries[s.Series()] {
+			seenSeries[s.Series()] = true
+			series = append(series, s.Series())
+		}
+		if !seenKeys[s.PublicKeys] {
+			seenKeys[s.PublicKeys] = true
+			publicKeys = append(publicKeys, s.PublicKeys)
+		}
+	}
+	sort.Strings(series)
+	sort.Ints(publicKeys)
+	return series, publicKeys
+}
@@ -0,0 +1,71 @@
+package results
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestSummarize reads a small CSV mixing two protocols, a file-level
+// "Average" row and an old file without Protocol and Curve columns, and
+// checks the grouping, order, means and standard deviations of Summarize.
+func TestSummarize(t *testing.T) {
+	file := `Protocol,Curve,Run,Duration (ms),Public Keys,Allocs per announcement
+ecpdksap,bn254,1,10.00,5000,2.00
+ecpdksap,bn254,2,14.00,5000,4.00
+ecpdksap,bn254,3,12.00,5000,3.00
+dksap,secp256k1,1,30.00,5000,1.00
+dksap,secp256k1,2,34.00,5000,1.00
+ecpdksap,bn254,1,40.00,10000,0.00
+ecpdksap,bn254,Average,12.00,5000,3.00
+`
+	records, err := Read(strings.NewReader(file), "unused", "unused")
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(records) != 6 {
+		t.Fatalf("Read: got %d records, expected 6 without the Average row", len(records))
+	}
+	old, err := Read(strings.NewReader("Run,Duration (ms),Public Keys\n1,5.00,5000\n2,7.00,5000\n"), "ecpsksap", "bn254")
+	if err != nil {
+		t.Fatalf("Read without Protocol and Curve: %v", err)
+	}
+	records = append(records, old...)
+
+	type summary struct {
+		key    Key
+		runs   int
+		mean   float64
+		stdDev float64
+		allocs float64
+	}
+	want := []summary{
+		{Key{"dksap", "secp256k1", 5000}, 2, 32, math.Sqrt(8), 1},
+		{Key{"ecpdksap", "bn254", 5000}, 3, 12, 2, 3},
+		{Key{"ecpdksap", "bn254", 10000}, 1, 40, 0, 0},
+		{Key{"ecpsksap", "bn254", 5000}, 2, 6, math.Sqrt(2), 0},
+	}
+	var got []summary
+	for _, s := range Summarize(records) {
+		got = append(got, summary{s.Key, s.Runs, s.Mean, s.StdDev, s.AllocsPerAnnouncement})
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Summarize: got %v, expected %v", got, want)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.key != w.key || g.runs != w.runs || math.Abs(g.mean-w.mean) > 1e-9 || math.Abs(g.stdDev-w.stdDev) > 1e-9 || math.Abs(g.allocs-w.allocs) > 1e-9 {
+			t.Fatalf("Summarize %d: got %+v, expected %+v", i, g, w)
+		}
+	}
+
+	series, publicKeys := Series(Summarize(records))
+	if !reflect.DeepEqual(series, []string{"dksap/secp256k1", "ecpdksap/bn254", "ecpsksap/bn254"}) || !reflect.DeepEqual(publicKeys, []int{5000, 10000}) {
+		t.Fatalf("Series: got %v and %v", series, publicKeys)
+	}
+
+	if _, err := Read(strings.NewReader("Run,Public Keys\n1,5000\n"), "", ""); err == nil {
+		t.Fatal("Read: accepted a file without a duration column")
+	}
+}
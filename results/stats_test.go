@@ -0,0 +1,49 @@
+package results
+
+import (
+	"math"
+	"testing"
+)
+
+// TestWelchTTest checks t, the degrees of freedom and the two-sided p-value
+// against reference values, and the degenerate cases: identical samples,
+// zero variance and samples too short to test. The first reference is the
+// Welch example on Wikipedia (t = -2.46, df = 24.99, p = 0.021); the
+// p-values were computed by integrating the t density numerically.
+func TestWelchTTest(t *testing.T) {
+	for i, c := range []struct {
+		a, b      []float64
+		t, df, p  float64
+		tolerance float64
+	}{
+		{
+			a: []float64{27.5, 21.0, 19.0, 23.6, 17.0, 17.9, 16.9, 20.1, 21.9, 22.6, 23.1, 19.6, 19.0, 21.7, 21.4},
+			b: []float64{27.1, 22.0, 20.8, 23.4, 23.4, 23.5, 25.8, 22.0, 24.8, 20.2, 21.9, 22.1, 22.9, 20.5, 24.4},
+			t: -2.45535639828601, df: 24.98852929023142, p: 0.021378001462861285, tolerance: 1e-9,
+		},
+		{
+			a: []float64{10.1, 10.4, 9.8, 10.0, 10.3, 9.9},
+			b: []float64{10.9, 11.6, 10.2, 12.1, 11.3, 10.7, 11.8, 12.4},
+			t: -4.620737769058811, df: 8.722584376161373, p: 0.0013607698495236816, tolerance: 1e-9,
+		},
+		// identical samples
+		{a: []float64{1, 2, 3, 4}, b: []float64{1, 2, 3, 4}, t: 0, df: 6, p: 1, tolerance: 1e-12},
+		// zero variance, equal and different means
+		{a: []float64{5, 5, 5}, b: []float64{5, 5}, t: 0, df: 3, p: 1, tolerance: 0},
+		{a: []float64{5, 5, 5}, b: []float64{7, 7, 7}, t: math.Inf(-1), df: 4, p: 0, tolerance: 0},
+		// too short
+		{a: []float64{1}, b: []float64{2, 3}, t: 0, df: 0, p: 1, tolerance: 0},
+	} {
+		gotT, gotDF, gotP := WelchTTest(c.a, c.b)
+		if !near(gotT, c.t, c.tolerance) || !near(gotDF, c.df, c.tolerance) || !near(gotP, c.p, c.tolerance) {
+			t.Errorf("case %d: got t=%v df=%v p=%v, expected t=%v df=%v p=%v", i, gotT, gotDF, gotP, c.t, c.df, c.p)
+		}
+	}
+}
+
+func near(got, want, tolerance float64) bool {
+	if math.IsInf(want, 0) {
+		return got == want
+	}
+	return math.Abs(got-want) <= tolerance*math.Max(1, math.Abs(want))
+}
@@ -0,0 +1,194 @@
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sap-go/hdkey"
+	"sap-go/protocol"
+	"sap-go/scanner"
+	"sort"
+)
+
+var (
+	// ErrNoEpoch is returned when no viewing key is active at an epoch.
+	ErrNoEpoch = errors.New("no viewing key active at epoch")
+	// ErrEpochOrder is returned when a viewing key is added for an epoch not
+	// after the latest one.
+	ErrEpochOrder = errors.New("epochs must be added in increasing order")
+	// ErrNoSeed is returned by Rotate on a keyring not created from a seed.
+	ErrNoSeed = errors.New("keyring has no seed to derive viewing keys from")
+)
+
+// EpochMetaAddress is a meta address together with the epoch its viewing key
+// became active.
+type EpochMetaAddress struct {
+	Epoch uint32
+	protocol.MetaAddress
+}
+
+// EpochRecipient is the recipient holding the viewing key of one epoch.
+type EpochRecipient struct {
+	Epoch     uint32
+	Recipient protocol.Recipient
+}
+
+// Match is an announcement found by Scan and the epoch whose viewing key found it.
+type Match struct {
+	Index int
+	Epoch uint32
+}
+
+// Keyring holds one spending key and the viewing keys it was rotated
+// through. The key added at epoch e is used by senders from e until the next
+// rotation, and senders may still pay it for Overlap epochs after that.
+type Keyring struct {
+	p        protocol.Protocol
+	spending protocol.Recipient
+	overlap  uint32
+	epochs   []EpochRecipient
+	seed     []byte
+	account  uint32
+}
+
+// NewKeyring creates a keyring for the spending key of r, which may be view
+// only. It holds no viewing keys until the first Add.
+func NewKeyring(p protocol.Protocol, r protocol.Recipient, overlap uint32) *Keyring {
+	return &Keyring{p: p, spending: r, overlap: overlap}
+}
+
+// FromSeed creates a keyring for the hdkey account of seed. Its Rotate
+// derives the viewing key of each epoch from the seed, so rotating through
+// the published epochs again restores the keyring.
+func FromSeed(p protocol.Protocol, seed []byte, account, overlap uint32) (*Keyring, error) {
+	r, err := hdkey.Recipient(p, seed, account)
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{p: p, spending: r, overlap: overlap, seed: seed, account: account}, nil
+}
+
+// Overlap is the number of epochs a replaced viewing key stays valid.
+func (kr *Keyring) Overlap() uint32 { return kr.overlap }
+
+// Add activates viewingKey from epoch on and returns the meta address senders
+// should use from then.
+func (kr *Keyring) Add(epoch uint32, viewingKey []byte) (EpochMetaAddress, error) {
+	if n := len(kr.epochs); n > 0 && epoch <= kr.epochs[n-1].Epoch {
+		return EpochMetaAddress{}, fmt.Errorf("%w: %d after %d", ErrEpochOrder, epoch, kr.epochs[n-1].Epoch)
+	}
+	var r protocol.Recipient
+	var err error
+	if spendingKey := kr.spending.SpendingKey(); spendingKey != nil {
+		r, err = kr.p.NewRecipient(spendingKey, viewingKey)
+	} else {
+		r, err = kr.p.NewViewOnlyRecipient(kr.spending.MetaAddress().Spend, viewingKey)
+	}
+	if err != nil {
+		return EpochMetaAddress{}, err
+	}
+	kr.epochs = append(kr.epochs, EpochRecipient{Epoch: epoch, Recipient: r})
+	return EpochMetaAddress{Epoch: epoch, MetaAddress: r.MetaAddress()}, nil
+}
+
+// Rotate activates the viewing key derived from the seed for epoch.
+func (kr *Keyring) Rotate(epoch uint32) (EpochMetaAddress, error) {
+	if kr.seed == nil {
+		return EpochMetaAddress{}, ErrNoSeed
+	}
+	viewingKey, err := hdkey.EpochViewingKey(kr.p, kr.seed, kr.account, epoch)
+	if err != nil {
+		return EpochMetaAddress{}, err
+	}
+	return kr.Add(epoch, viewingKey)
+}
+
+// Epochs returns the viewing keys in the order they were added.
+func (kr *Keyring) Epochs() []EpochRecipient {
+	return append([]EpochRecipient(nil), kr.epochs...)
+}
+
+// MetaAddress returns the meta address senders should use at epoch.
+func (kr *Keyring) MetaAddress(epoch uint32) (EpochMetaAddress, error) {
+	i := kr.active(epoch)
+	if i < 0 {
+		return EpochMetaAddress{}, fmt.Errorf("%w %d", ErrNoEpoch, epoch)
+	}
+	return EpochMetaAddress{Epoch: kr.epochs[i].Epoch, MetaAddress: kr.epochs[i].Recipient.MetaAddress()}, nil
+}
+
+// active returns the index of the viewing key senders use at epoch, or -1.
+func (kr *Keyring) active(epoch uint32) int {
+	return sort.Search(len(kr.epochs), func(i int) bool { return kr.epochs[i].Epoch > epoch }) - 1
+}
+
+// Valid returns the viewing keys senders may pay at epoch: the active one and
+// those replaced less than Overlap epochs ago, newest first.
+func (kr *Keyring) Valid(epoch uint32) []EpochRecipient {
+	var valid []EpochRecipient
+	for i := kr.active(epoch); i >= 0; i-- {
+		if i+1 < len(kr.epochs) && uint64(epoch) >= uint64(kr.epochs[i+1].Epoch)+uint64(kr.overlap) {
+			break
+		}
+		valid = append(valid, kr.epochs[i])
+	}
+	return valid
+}
+
+// Scan returns the announcements addressed to any viewing key of the keyring,
+// sorted by index. Every key is tried, whatever the current epoch, so
+// rescanning history still finds payments to retired keys. The keys share a
+// single scanner.ScanBatch pass, and an announcement found by several keys
+// is reported once, with the newest of them.
+func (kr *Keyring) Scan(announcements []protocol.Decoded, viewTags bool) ([]Match, scanner.Stats, error) {
+	if len(kr.epochs) == 0 {
+		return nil, scanner.Stats{}, nil
+	}
+	recipients := make([]protocol.Recipient, len(kr.epochs))
+	for i, e := range kr.epochs {
+		recipients[len(kr.epochs)-1-i] = e.Recipient
+	}
+	indices, stats, err := scanner.ScanBatch(kr.p, recipients, scanner.Slice(announcements), runtime.GOMAXPROCS(0), viewTags)
+	if err != nil {
+		return nil, stats, err
+	}
+	var matches []Match
+	found := make(map[int]bool)
+	for j, epochIndices := range indices {
+		for _, i := range epochIndices {
+			if !found[i] {
+				found[i] = true
+				matches = append(matches, Match{Index: i, Epoch: kr.epochs[len(kr.epochs)-1-j].Epoch})
+			}
+		}
+	}
+	stats.Matches = len(matches)
+	sort.Slice(matches, func(a, b int) bool { return matches[a].Index < matches[b].Index })
+	return matches, stats, nil
+}
+
+// Derive computes the stealth private key of an announcement found with the
+// viewing key of epoch.
+func (kr *Keyring) Derive(epoch uint32, ephemeral protocol.Ephemeral) ([]byte, error) {
+	for _, e := range kr.epochs {
+		if e.Epoch == epoch {
+			return kr.p.Derive(e.Recipient, ephemeral)
+		}
+	}
+	return nil, fmt.Errorf("%w %d", ErrNoEpoch, epoch)
+}
+
+// Current picks the meta address a sender should use at epoch among those a
+// recipient published: the one with the latest epoch not after it.
+func Current(published []EpochMetaAddress, epoch uint32) (EpochMetaAddress, error) {
+	best := -1
+	for i, m := range published {
+		if m.Epoch <= epoch && (best < 0 || m.Epoch > published[best].Epoch) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return EpochMetaAddress{}, fmt.Errorf("%w %d", ErrNoEpoch, epoch)
+	}
+	return published[best], nil
+}
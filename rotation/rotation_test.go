@@ -0,0 +1,159 @@
+package rotation
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sap-go/hdkey"
+	"sap-go/protocol"
+	"testing"
+)
+
+// TestKeyring rotates a viewing key of every protocol through epochs 1, 3
+// and 6 with an overlap of one epoch, checks which keys are valid at every
+// epoch and that the keyring can be restored from its seed, sends a payment
+// to each meta address and checks that a scan finds all of them, for both a
+// full and a view-only keyring.
+func TestKeyring(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testKeyring(t, p) })
+	}
+}
+
+func testKeyring(t *testing.T, p protocol.Protocol) {
+	seed := bytes.Repeat([]byte{0x55}, 32)
+	owner, err := hdkey.Recipient(p, seed, 0)
+	if err != nil {
+		t.Fatalf("hdkey.Recipient: %v", err)
+	}
+	viewOnly, err := p.NewViewOnlyRecipient(owner.MetaAddress().Spend, owner.ViewingKey())
+	if err != nil {
+		t.Fatalf("NewViewOnlyRecipient: %v", err)
+	}
+	kr, err := FromSeed(p, seed, 0, 1)
+	if err != nil {
+		t.Fatalf("FromSeed: %v", err)
+	}
+	watcher := NewKeyring(p, viewOnly, 1)
+	if _, err := watcher.Rotate(1); !errors.Is(err, ErrNoSeed) {
+		t.Fatalf("Rotate without a seed: got %v, expected %v", err, ErrNoSeed)
+	}
+
+	if _, err := kr.MetaAddress(0); !errors.Is(err, ErrNoEpoch) {
+		t.Fatalf("MetaAddress before the first epoch: got %v, expected %v", err, ErrNoEpoch)
+	}
+	var published []EpochMetaAddress
+	for _, epoch := range []uint32{1, 3, 6} {
+		meta, err := kr.Rotate(epoch)
+		if err != nil {
+			t.Fatalf("Rotate(%d): %v", epoch, err)
+		}
+		if _, err := watcher.Add(epoch, kr.Epochs()[len(published)].Recipient.ViewingKey()); err != nil {
+			t.Fatalf("view-only Add(%d): %v", epoch, err)
+		}
+		published = append(published, meta)
+	}
+	if _, err := kr.Rotate(6); !errors.Is(err, ErrEpochOrder) {
+		t.Fatalf("Rotate to the same epoch: got %v, expected %v", err, ErrEpochOrder)
+	}
+
+	// Rotating a keyring of the same seed through the published epochs
+	// restores the same meta addresses.
+	restored, err := FromSeed(p, seed, 0, 1)
+	if err != nil {
+		t.Fatalf("FromSeed: %v", err)
+	}
+	for _, meta := range published {
+		if again, err := restored.Rotate(meta.Epoch); err != nil || !reflect.DeepEqual(again, meta) {
+			t.Fatalf("restored Rotate(%d): got %v (err: %v), expected %v", meta.Epoch, again, err, meta)
+		}
+	}
+
+	// The key of epoch 1 is valid through epoch 3, that of epoch 3 through
+	// epoch 6, and that of epoch 6 from then on.
+	valid := [][]uint32{
+		0: nil,
+		1: {1},
+		2: {1},
+		3: {3, 1},
+		4: {3},
+		5: {3},
+		6: {6, 3},
+		7: {6},
+	}
+	for epoch, want := range valid {
+		var got []uint32
+		for _, e := range kr.Valid(uint32(epoch)) {
+			got = append(got, e.Epoch)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("Valid(%d): got epochs %v, expected %v", epoch, got, want)
+		}
+	}
+
+	// Announcement i pays the meta address a sender picks at epoch sentAt[i];
+	// announcement 3 is unrelated.
+	sentAt := []uint32{2, 4, 7}
+	var anns []protocol.Decoded
+	for _, epoch := range sentAt {
+		meta, err := Current(published, epoch)
+		if err != nil {
+			t.Fatalf("Current(%d): %v", epoch, err)
+		}
+		if active, _ := kr.MetaAddress(epoch); !reflect.DeepEqual(active, meta) {
+			t.Fatalf("epoch %d: sender picked the meta address of epoch %d, keyring has %d", epoch, meta.Epoch, active.Epoch)
+		}
+		ann, err := p.Send(meta.MetaAddress)
+		if err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+		decoded, err := protocol.Decode(p, ann)
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		anns = append(anns, decoded)
+	}
+	noise, err := p.RandomAnnouncement()
+	if err != nil {
+		t.Fatalf("RandomAnnouncement: %v", err)
+	}
+	decoded, err := protocol.Decode(p, noise)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	anns = append(anns, decoded)
+
+	// Every key is scanned with, including those no longer valid.
+	expected := []Match{{0, 1}, {1, 3}, {2, 6}}
+	for _, k := range []*Keyring{kr, watcher} {
+		for _, viewTags := range []bool{false, true} {
+			got, stats, err := k.Scan(anns, viewTags)
+			if err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if !reflect.DeepEqual(got, expected) || stats.Matches != len(expected) {
+				t.Fatalf("Scan: got %v, expected %v", got, expected)
+			}
+		}
+	}
+
+	for i, m := range expected {
+		privateKey, err := kr.Derive(m.Epoch, anns[m.Index].Ephemeral)
+		if err != nil {
+			t.Fatalf("Derive %d: %v", i, err)
+		}
+		publicKey, err := p.PublicKey(privateKey)
+		if err != nil {
+			t.Fatalf("PublicKey: %v", err)
+		}
+		if protocol.AddressFromPublicKey(publicKey) != anns[m.Index].Address {
+			t.Fatalf("Derive %d: private key does not control the announced address", i)
+		}
+		if _, err := watcher.Derive(m.Epoch, anns[m.Index].Ephemeral); !errors.Is(err, protocol.ErrViewOnly) {
+			t.Fatalf("view-only Derive: got %v, expected %v", err, protocol.ErrViewOnly)
+		}
+	}
+	if _, err := kr.Derive(2, anns[0].Ephemeral); !errors.Is(err, ErrNoEpoch) {
+		t.Fatalf("Derive at an epoch without a key: got %v, expected %v", err, ErrNoEpoch)
+	}
+}
@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"sap-go/annfile"
+	"sap-go/protocol"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// inProcessBuffer is the size of the in-memory connection buffer.
+const inProcessBuffer = 1 << 20
+
+// inProcess serves s over an in-memory listener and returns a client
+// connected to it, which is closed with the test.
+func inProcess(t *testing.T, s *Server) StealthAddressClient {
+	listener := bufconn.Listen(inProcessBuffer)
+	server := grpc.NewServer()
+	RegisterStealthAddressServer(server, s)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return NewStealthAddressClient(conn)
+}
+
+// TestService runs the gRPC service through an in-process client for every
+// protocol: payments generated by the server are found by a scan streamed
+// for their recipient, view tags match the announcements, a scan can resume
+// from an index and malformed requests fail with InvalidArgument.
+func TestService(t *testing.T) {
+	s := NewServer()
+	client := inProcess(t, s)
+	ctx := context.Background()
+
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testService(ctx, t, s, client, p) })
+	}
+}
+
+func testService(ctx context.Context, t *testing.T, s *Server, client StealthAddressClient, p protocol.Protocol) {
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := r.MetaAddress()
+	credential := &ViewingCredential{
+		Protocol:    p.Name(),
+		Curve:       p.Curve(),
+		MetaAddress: &MetaAddress{Spend: meta.Spend, View: meta.View},
+		ViewingKey:  r.ViewingKey(),
+	}
+
+	// Payments at 1 and 4 are generated through the service, the rest is noise.
+	var records []annfile.Record
+	var paid []uint64
+	for i := 0; i < 6; i++ {
+		var ann protocol.Announcement
+		if i == 1 || i == 4 {
+			resp, err := client.GenerateStealthAddress(ctx, &GenerateStealthAddressRequest{
+				Protocol: p.Name(), Curve: p.Curve(), MetaAddress: credential.MetaAddress,
+			})
+			if err != nil {
+				t.Fatalf("GenerateStealthAddress: %v", err)
+			}
+			if !bytes.Equal(resp.StealthAddress, resp.Announcement.Address) {
+				t.Fatal("GenerateStealthAddress: stealth address differs from the announcement")
+			}
+			ann = protocol.Announcement{Ephemeral: resp.Announcement.Ephemeral, ViewTag: uint8(resp.Announcement.ViewTag)}
+			copy(ann.Address[:], resp.Announcement.Address)
+
+			tag, err := client.ComputeViewTag(ctx, &ComputeViewTagRequest{Credential: credential, Ephemeral: ann.Ephemeral})
+			if err != nil {
+				t.Fatalf("ComputeViewTag: %v", err)
+			}
+			if tag.ViewTag != resp.Announcement.ViewTag {
+				t.Fatalf("ComputeViewTag: got %d, announced %d", tag.ViewTag, resp.Announcement.ViewTag)
+			}
+			paid = append(paid, uint64(i))
+		} else if ann, err = p.RandomAnnouncement(); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, annfile.NewRecord(p, ann))
+	}
+	if err := s.Add(records); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		start        uint64
+		skipViewTags bool
+		expected     []uint64
+	}{
+		{0, false, paid},
+		{0, true, paid},
+		{paid[0] + 1, false, paid[1:]},
+		{uint64(len(records)), false, nil},
+	} {
+		got, err := scan(ctx, client, &ScanRequest{Credential: credential, Start: c.start, SkipViewTags: c.skipViewTags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var indices []uint64
+		for _, result := range got {
+			indices = append(indices, result.Index)
+			address := protocol.AddressFromPublicKey(result.StealthPublicKey)
+			if !bytes.Equal(address[:], result.Announcement.Address) {
+				t.Fatalf("Scan: result %d: stealth public key does not hash to the address", result.Index)
+			}
+		}
+		if !reflect.DeepEqual(indices, c.expected) {
+			t.Fatalf("Scan from %d: got %v, expected %v", c.start, indices, c.expected)
+		}
+	}
+
+	other, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongKey := &ViewingCredential{Protocol: p.Name(), Curve: p.Curve(), MetaAddress: credential.MetaAddress, ViewingKey: other.ViewingKey()}
+	for name, call := range map[string]func() error{
+		"unknown protocol": func() error {
+			_, err := client.GenerateStealthAddress(ctx, &GenerateStealthAddressRequest{Protocol: "none", Curve: p.Curve(), MetaAddress: credential.MetaAddress})
+			return err
+		},
+		"truncated meta address": func() error {
+			_, err := client.GenerateStealthAddress(ctx, &GenerateStealthAddressRequest{
+				Protocol: p.Name(), Curve: p.Curve(), MetaAddress: &MetaAddress{Spend: meta.Spend[1:], View: meta.View},
+			})
+			return err
+		},
+		"missing credential": func() error {
+			_, err := client.ComputeViewTag(ctx, &ComputeViewTagRequest{Ephemeral: records[0].Ephemeral})
+			return err
+		},
+		"truncated ephemeral key": func() error {
+			_, err := client.ComputeViewTag(ctx, &ComputeViewTagRequest{Credential: credential, Ephemeral: records[0].Ephemeral[1:]})
+			return err
+		},
+		"foreign viewing key": func() error {
+			_, err := scan(ctx, client, &ScanRequest{Credential: wrongKey})
+			return err
+		},
+	} {
+		if code := status.Code(call()); code != codes.InvalidArgument {
+			t.Errorf("%s: got %v, expected %v", name, code, codes.InvalidArgument)
+		}
+	}
+}
+
+// scan collects the results streamed by Scan.
+func scan(ctx context.Context, client StealthAddressClient, req *ScanRequest) ([]*ScanResult, error) {
+	stream, err := client.Scan(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	var results []*ScanResult
+	for {
+		result, err := stream.Recv()
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+}
@@ -0,0 +1,832 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: sap.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// MetaAddress holds the encoded spending and viewing public keys of a recipient.
+type MetaAddress struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Spend []byte `protobuf:"bytes,1,opt,name=spend,proto3" json:"spend,omitempty"`
+	View  []byte `protobuf:"bytes,2,opt,name=view,proto3" json:"view,omitempty"`
+}
+
+func (x *MetaAddress) Reset() {
+	*x = MetaAddress{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MetaAddress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetaAddress) ProtoMessage() {}
+
+func (x *MetaAddress) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetaAddress.ProtoReflect.Descriptor instead.
+func (*MetaAddress) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MetaAddress) GetSpend() []byte {
+	if x != nil {
+		return x.Spend
+	}
+	return nil
+}
+
+func (x *MetaAddress) GetView() []byte {
+	if x != nil {
+		return x.View
+	}
+	return nil
+}
+
+// Announcement is published by a sender alongside every payment.
+type Announcement struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ephemeral []byte `protobuf:"bytes,1,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+	ViewTag   uint32 `protobuf:"varint,2,opt,name=view_tag,json=viewTag,proto3" json:"view_tag,omitempty"`
+	Address   []byte `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+}
+
+func (x *Announcement) Reset() {
+	*x = Announcement{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Announcement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Announcement) ProtoMessage() {}
+
+func (x *Announcement) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Announcement.ProtoReflect.Descriptor instead.
+func (*Announcement) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Announcement) GetEphemeral() []byte {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return nil
+}
+
+func (x *Announcement) GetViewTag() uint32 {
+	if x != nil {
+		return x.ViewTag
+	}
+	return 0
+}
+
+func (x *Announcement) GetAddress() []byte {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+// ViewingCredential identifies a recipient by meta address and viewing
+// private key. It lets the server scan but not spend.
+type ViewingCredential struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Protocol    string       `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Curve       string       `protobuf:"bytes,2,opt,name=curve,proto3" json:"curve,omitempty"`
+	MetaAddress *MetaAddress `protobuf:"bytes,3,opt,name=meta_address,json=metaAddress,proto3" json:"meta_address,omitempty"`
+	ViewingKey  []byte       `protobuf:"bytes,4,opt,name=viewing_key,json=viewingKey,proto3" json:"viewing_key,omitempty"`
+}
+
+func (x *ViewingCredential) Reset() {
+	*x = ViewingCredential{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ViewingCredential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ViewingCredential) ProtoMessage() {}
+
+func (x *ViewingCredential) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ViewingCredential.ProtoReflect.Descriptor instead.
+func (*ViewingCredential) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ViewingCredential) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *ViewingCredential) GetCurve() string {
+	if x != nil {
+		return x.Curve
+	}
+	return ""
+}
+
+func (x *ViewingCredential) GetMetaAddress() *MetaAddress {
+	if x != nil {
+		return x.MetaAddress
+	}
+	return nil
+}
+
+func (x *ViewingCredential) GetViewingKey() []byte {
+	if x != nil {
+		return x.ViewingKey
+	}
+	return nil
+}
+
+type GenerateStealthAddressRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Protocol    string       `protobuf:"bytes,1,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Curve       string       `protobuf:"bytes,2,opt,name=curve,proto3" json:"curve,omitempty"`
+	MetaAddress *MetaAddress `protobuf:"bytes,3,opt,name=meta_address,json=metaAddress,proto3" json:"meta_address,omitempty"`
+}
+
+func (x *GenerateStealthAddressRequest) Reset() {
+	*x = GenerateStealthAddressRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateStealthAddressRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateStealthAddressRequest) ProtoMessage() {}
+
+func (x *GenerateStealthAddressRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateStealthAddressRequest.ProtoReflect.Descriptor instead.
+func (*GenerateStealthAddressRequest) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GenerateStealthAddressRequest) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *GenerateStealthAddressRequest) GetCurve() string {
+	if x != nil {
+		return x.Curve
+	}
+	return ""
+}
+
+func (x *GenerateStealthAddressRequest) GetMetaAddress() *MetaAddress {
+	if x != nil {
+		return x.MetaAddress
+	}
+	return nil
+}
+
+type GenerateStealthAddressResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Announcement   *Announcement `protobuf:"bytes,1,opt,name=announcement,proto3" json:"announcement,omitempty"`
+	StealthAddress []byte        `protobuf:"bytes,2,opt,name=stealth_address,json=stealthAddress,proto3" json:"stealth_address,omitempty"`
+}
+
+func (x *GenerateStealthAddressResponse) Reset() {
+	*x = GenerateStealthAddressResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateStealthAddressResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateStealthAddressResponse) ProtoMessage() {}
+
+func (x *GenerateStealthAddressResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateStealthAddressResponse.ProtoReflect.Descriptor instead.
+func (*GenerateStealthAddressResponse) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GenerateStealthAddressResponse) GetAnnouncement() *Announcement {
+	if x != nil {
+		return x.Announcement
+	}
+	return nil
+}
+
+func (x *GenerateStealthAddressResponse) GetStealthAddress() []byte {
+	if x != nil {
+		return x.StealthAddress
+	}
+	return nil
+}
+
+type ComputeViewTagRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Credential *ViewingCredential `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	Ephemeral  []byte             `protobuf:"bytes,2,opt,name=ephemeral,proto3" json:"ephemeral,omitempty"`
+}
+
+func (x *ComputeViewTagRequest) Reset() {
+	*x = ComputeViewTagRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComputeViewTagRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeViewTagRequest) ProtoMessage() {}
+
+func (x *ComputeViewTagRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeViewTagRequest.ProtoReflect.Descriptor instead.
+func (*ComputeViewTagRequest) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ComputeViewTagRequest) GetCredential() *ViewingCredential {
+	if x != nil {
+		return x.Credential
+	}
+	return nil
+}
+
+func (x *ComputeViewTagRequest) GetEphemeral() []byte {
+	if x != nil {
+		return x.Ephemeral
+	}
+	return nil
+}
+
+type ComputeViewTagResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ViewTag uint32 `protobuf:"varint,1,opt,name=view_tag,json=viewTag,proto3" json:"view_tag,omitempty"`
+}
+
+func (x *ComputeViewTagResponse) Reset() {
+	*x = ComputeViewTagResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ComputeViewTagResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComputeViewTagResponse) ProtoMessage() {}
+
+func (x *ComputeViewTagResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComputeViewTagResponse.ProtoReflect.Descriptor instead.
+func (*ComputeViewTagResponse) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ComputeViewTagResponse) GetViewTag() uint32 {
+	if x != nil {
+		return x.ViewTag
+	}
+	return 0
+}
+
+type ScanRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Credential *ViewingCredential `protobuf:"bytes,1,opt,name=credential,proto3" json:"credential,omitempty"`
+	// start is the index of the first announcement to scan.
+	Start uint64 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	// skip_view_tags derives the stealth public key of every announcement
+	// instead of filtering by view tag first.
+	SkipViewTags bool `protobuf:"varint,3,opt,name=skip_view_tags,json=skipViewTags,proto3" json:"skip_view_tags,omitempty"`
+}
+
+func (x *ScanRequest) Reset() {
+	*x = ScanRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanRequest) ProtoMessage() {}
+
+func (x *ScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ScanRequest) GetCredential() *ViewingCredential {
+	if x != nil {
+		return x.Credential
+	}
+	return nil
+}
+
+func (x *ScanRequest) GetStart() uint64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *ScanRequest) GetSkipViewTags() bool {
+	if x != nil {
+		return x.SkipViewTags
+	}
+	return false
+}
+
+// ScanResult is an announcement addressed to the recipient.
+type ScanResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Index            uint64        `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Announcement     *Announcement `protobuf:"bytes,2,opt,name=announcement,proto3" json:"announcement,omitempty"`
+	StealthPublicKey []byte        `protobuf:"bytes,3,opt,name=stealth_public_key,json=stealthPublicKey,proto3" json:"stealth_public_key,omitempty"`
+}
+
+func (x *ScanResult) Reset() {
+	*x = ScanResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_sap_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResult) ProtoMessage() {}
+
+func (x *ScanResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sap_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResult.ProtoReflect.Descriptor instead.
+func (*ScanResult) Descriptor() ([]byte, []int) {
+	return file_sap_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScanResult) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ScanResult) GetAnnouncement() *Announcement {
+	if x != nil {
+		return x.Announcement
+	}
+	return nil
+}
+
+func (x *ScanResult) GetStealthPublicKey() []byte {
+	if x != nil {
+		return x.StealthPublicKey
+	}
+	return nil
+}
+
+var File_sap_proto protoreflect.FileDescriptor
+
+var file_sap_proto_rawDesc = []byte{
+	0x0a, 0x09, 0x73, 0x61, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x73, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x22, 0x37, 0x0a, 0x0b, 0x4d, 0x65, 0x74, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x05, 0x73, 0x70, 0x65, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x76, 0x69, 0x65, 0x77,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x76, 0x69, 0x65, 0x77, 0x22, 0x61, 0x0a, 0x0c,
+	0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65, 0x72, 0x61, 0x6c, 0x12, 0x19, 0x0a, 0x08, 0x76, 0x69,
+	0x65, 0x77, 0x5f, 0x74, 0x61, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x69,
+	0x65, 0x77, 0x54, 0x61, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22,
+	0x9e, 0x01, 0x0a, 0x11, 0x56, 0x69, 0x65, 0x77, 0x69, 0x6e, 0x67, 0x43, 0x72, 0x65, 0x64, 0x65,
+	0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f,
+	0x6c, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x75, 0x72, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x63, 0x75, 0x72, 0x76, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x6d, 0x65, 0x74, 0x61, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e,
+	0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x52, 0x0b, 0x6d, 0x65, 0x74, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12,
+	0x1f, 0x0a, 0x0b, 0x76, 0x69, 0x65, 0x77, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x76, 0x69, 0x65, 0x77, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79,
+	0x22, 0x89, 0x01, 0x0a, 0x1d, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x14,
+	0x0a, 0x05, 0x63, 0x75, 0x72, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x63,
+	0x75, 0x72, 0x76, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x61, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x73, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52,
+	0x0b, 0x6d, 0x65, 0x74, 0x61, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x83, 0x01, 0x0a,
+	0x1e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x38, 0x0a, 0x0c, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41,
+	0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x0c, 0x61, 0x6e, 0x6e,
+	0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x27, 0x0a, 0x0f, 0x73, 0x74, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0e, 0x73, 0x74, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65,
+	0x73, 0x73, 0x22, 0x70, 0x0a, 0x15, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x56, 0x69, 0x65,
+	0x77, 0x54, 0x61, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63,
+	0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x19, 0x2e, 0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x69, 0x6e, 0x67,
+	0x43, 0x72, 0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x12, 0x1c, 0x0a, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d, 0x65,
+	0x72, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x65, 0x70, 0x68, 0x65, 0x6d,
+	0x65, 0x72, 0x61, 0x6c, 0x22, 0x33, 0x0a, 0x16, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x56,
+	0x69, 0x65, 0x77, 0x54, 0x61, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x19,
+	0x0a, 0x08, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x76, 0x69, 0x65, 0x77, 0x54, 0x61, 0x67, 0x22, 0x84, 0x01, 0x0a, 0x0b, 0x53, 0x63,
+	0x61, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x39, 0x0a, 0x0a, 0x63, 0x72, 0x65,
+	0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x69, 0x65, 0x77, 0x69, 0x6e, 0x67, 0x43, 0x72,
+	0x65, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x61, 0x6c, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x64, 0x65, 0x6e,
+	0x74, 0x69, 0x61, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x73, 0x6b,
+	0x69, 0x70, 0x5f, 0x76, 0x69, 0x65, 0x77, 0x5f, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0c, 0x73, 0x6b, 0x69, 0x70, 0x56, 0x69, 0x65, 0x77, 0x54, 0x61, 0x67, 0x73,
+	0x22, 0x8a, 0x01, 0x0a, 0x0a, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12,
+	0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x38, 0x0a, 0x0c, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63,
+	0x65, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x73, 0x61,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x52, 0x0c, 0x61, 0x6e, 0x6e, 0x6f, 0x75, 0x6e, 0x63, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x2c, 0x0a, 0x12, 0x73, 0x74, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69,
+	0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x10, 0x73, 0x74, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x32, 0xfd, 0x01,
+	0x0a, 0x0e, 0x53, 0x74, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x12, 0x67, 0x0a, 0x16, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x25, 0x2e, 0x73, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x26, 0x2e, 0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72,
+	0x61, 0x74, 0x65, 0x53, 0x74, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x0e, 0x43, 0x6f, 0x6d,
+	0x70, 0x75, 0x74, 0x65, 0x56, 0x69, 0x65, 0x77, 0x54, 0x61, 0x67, 0x12, 0x1d, 0x2e, 0x73, 0x61,
+	0x70, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x56, 0x69, 0x65, 0x77,
+	0x54, 0x61, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x61, 0x70,
+	0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x56, 0x69, 0x65, 0x77, 0x54,
+	0x61, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x04, 0x53, 0x63,
+	0x61, 0x6e, 0x12, 0x13, 0x2e, 0x73, 0x61, 0x70, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x73, 0x61, 0x70, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x63, 0x61, 0x6e, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x30, 0x01, 0x42, 0x0c, 0x5a,
+	0x0a, 0x73, 0x61, 0x70, 0x2d, 0x67, 0x6f, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_sap_proto_rawDescOnce sync.Once
+	file_sap_proto_rawDescData = file_sap_proto_rawDesc
+)
+
+func file_sap_proto_rawDescGZIP() []byte {
+	file_sap_proto_rawDescOnce.Do(func() {
+		file_sap_proto_rawDescData = protoimpl.X.CompressGZIP(file_sap_proto_rawDescData)
+	})
+	return file_sap_proto_rawDescData
+}
+
+var file_sap_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_sap_proto_goTypes = []any{
+	(*MetaAddress)(nil),                    // 0: sap.v1.MetaAddress
+	(*Announcement)(nil),                   // 1: sap.v1.Announcement
+	(*ViewingCredential)(nil),              // 2: sap.v1.ViewingCredential
+	(*GenerateStealthAddressRequest)(nil),  // 3: sap.v1.GenerateStealthAddressRequest
+	(*GenerateStealthAddressResponse)(nil), // 4: sap.v1.GenerateStealthAddressResponse
+	(*ComputeViewTagRequest)(nil),          // 5: sap.v1.ComputeViewTagRequest
+	(*ComputeViewTagResponse)(nil),         // 6: sap.v1.ComputeViewTagResponse
+	(*ScanRequest)(nil),                    // 7: sap.v1.ScanRequest
+	(*ScanResult)(nil),                     // 8: sap.v1.ScanResult
+}
+var file_sap_proto_depIdxs = []int32{
+	0, // 0: sap.v1.ViewingCredential.meta_address:type_name -> sap.v1.MetaAddress
+	0, // 1: sap.v1.GenerateStealthAddressRequest.meta_address:type_name -> sap.v1.MetaAddress
+	1, // 2: sap.v1.GenerateStealthAddressResponse.announcement:type_name -> sap.v1.Announcement
+	2, // 3: sap.v1.ComputeViewTagRequest.credential:type_name -> sap.v1.ViewingCredential
+	2, // 4: sap.v1.ScanRequest.credential:type_name -> sap.v1.ViewingCredential
+	1, // 5: sap.v1.ScanResult.announcement:type_name -> sap.v1.Announcement
+	3, // 6: sap.v1.StealthAddress.GenerateStealthAddress:input_type -> sap.v1.GenerateStealthAddressRequest
+	5, // 7: sap.v1.StealthAddress.ComputeViewTag:input_type -> sap.v1.ComputeViewTagRequest
+	7, // 8: sap.v1.StealthAddress.Scan:input_type -> sap.v1.ScanRequest
+	4, // 9: sap.v1.StealthAddress.GenerateStealthAddress:output_type -> sap.v1.GenerateStealthAddressResponse
+	6, // 10: sap.v1.StealthAddress.ComputeViewTag:output_type -> sap.v1.ComputeViewTagResponse
+	8, // 11: sap.v1.StealthAddress.Scan:output_type -> sap.v1.ScanResult
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_sap_proto_init() }
+func file_sap_proto_init() {
+	if File_sap_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_sap_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*MetaAddress); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*Announcement); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*ViewingCredential); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*GenerateStealthAddressRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GenerateStealthAddressResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ComputeViewTagRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*ComputeViewTagResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[7].Exporter = func(v any, i int) any {
+			switch v := v.(*ScanRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_sap_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*ScanResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_sap_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sap_proto_goTypes,
+		DependencyIndexes: file_sap_proto_depIdxs,
+		MessageInfos:      file_sap_proto_msgTypes,
+	}.Build()
+	File_sap_proto = out.File
+	file_sap_proto_rawDesc = nil
+	file_sap_proto_goTypes = nil
+	file_sap_proto_depIdxs = nil
+}
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: sap.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	StealthAddress_GenerateStealthAddress_FullMethodName = "/sap.v1.StealthAddress/GenerateStealthAddress"
+	StealthAddress_ComputeViewTag_FullMethodName         = "/sap.v1.StealthAddress/ComputeViewTag"
+	StealthAddress_Scan_FullMethodName                   = "/sap.v1.StealthAddress/Scan"
+)
+
+// StealthAddressClient is the client API for StealthAddress service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// StealthAddress sends to meta addresses and scans announcements for
+// recipients who share their viewing key.
+type StealthAddressClient interface {
+	// GenerateStealthAddress creates an announcement paying the owner of a meta address.
+	GenerateStealthAddress(ctx context.Context, in *GenerateStealthAddressRequest, opts ...grpc.CallOption) (*GenerateStealthAddressResponse, error)
+	// ComputeViewTag computes the view tag a recipient expects for an ephemeral key.
+	ComputeViewTag(ctx context.Context, in *ComputeViewTagRequest, opts ...grpc.CallOption) (*ComputeViewTagResponse, error)
+	// Scan streams the announcements held by the server that are addressed to a recipient.
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResult], error)
+}
+
+type stealthAddressClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStealthAddressClient(cc grpc.ClientConnInterface) StealthAddressClient {
+	return &stealthAddressClient{cc}
+}
+
+func (c *stealthAddressClient) GenerateStealthAddress(ctx context.Context, in *GenerateStealthAddressRequest, opts ...grpc.CallOption) (*GenerateStealthAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GenerateStealthAddressResponse)
+	err := c.cc.Invoke(ctx, StealthAddress_GenerateStealthAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stealthAddressClient) ComputeViewTag(ctx context.Context, in *ComputeViewTagRequest, opts ...grpc.CallOption) (*ComputeViewTagResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ComputeViewTagResponse)
+	err := c.cc.Invoke(ctx, StealthAddress_ComputeViewTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *stealthAddressClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScanResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &StealthAddress_ServiceDesc.Streams[0], StealthAddress_Scan_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScanRequest, ScanResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StealthAddress_ScanClient = grpc.ServerStreamingClient[ScanResult]
+
+// StealthAddressServer is the server API for StealthAddress service.
+// All implementations must embed UnimplementedStealthAddressServer
+// for forward compatibility.
+//
+// StealthAddress sends to meta addresses and scans announcements for
+// recipients who share their viewing key.
+type StealthAddressServer interface {
+	// GenerateStealthAddress creates an announcement paying the owner of a meta address.
+	GenerateStealthAddress(context.Context, *GenerateStealthAddressRequest) (*GenerateStealthAddressResponse, error)
+	// ComputeViewTag computes the view tag a recipient expects for an ephemeral key.
+	ComputeViewTag(context.Context, *ComputeViewTagRequest) (*ComputeViewTagResponse, error)
+	// Scan streams the announcements held by the server that are addressed to a recipient.
+	Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResult]) error
+	mustEmbedUnimplementedStealthAddressServer()
+}
+
+// UnimplementedStealthAddressServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStealthAddressServer struct{}
+
+func (UnimplementedStealthAddressServer) GenerateStealthAddress(context.Context, *GenerateStealthAddressRequest) (*GenerateStealthAddressResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateStealthAddress not implemented")
+}
+func (UnimplementedStealthAddressServer) ComputeViewTag(context.Context, *ComputeViewTagRequest) (*ComputeViewTagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ComputeViewTag not implemented")
+}
+func (UnimplementedStealthAddressServer) Scan(*ScanRequest, grpc.ServerStreamingServer[ScanResult]) error {
+	return status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedStealthAddressServer) mustEmbedUnimplementedStealthAddressServer() {}
+func (UnimplementedStealthAddressServer) testEmbeddedByValue()                        {}
+
+// UnsafeStealthAddressServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StealthAddressServer will
+// result in compilation errors.
+type UnsafeStealthAddressServer interface {
+	mustEmbedUnimplementedStealthAddressServer()
+}
+
+func RegisterStealthAddressServer(s grpc.ServiceRegistrar, srv StealthAddressServer) {
+	// If the following call pancis, it indicates UnimplementedStealthAddressServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&StealthAddress_ServiceDesc, srv)
+}
+
+func _StealthAddress_GenerateStealthAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateStealthAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StealthAddressServer).GenerateStealthAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StealthAddress_GenerateStealthAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StealthAddressServer).GenerateStealthAddress(ctx, req.(*GenerateStealthAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StealthAddress_ComputeViewTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ComputeViewTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StealthAddressServer).ComputeViewTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StealthAddress_ComputeViewTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StealthAddressServer).ComputeViewTag(ctx, req.(*ComputeViewTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StealthAddress_Scan_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScanRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StealthAddressServer).Scan(m, &grpc.GenericServerStream[ScanRequest, ScanResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type StealthAddress_ScanServer = grpc.ServerStreamingServer[ScanResult]
+
+// StealthAddress_ServiceDesc is the grpc.ServiceDesc for StealthAddress service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var StealthAddress_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sap.v1.StealthAddress",
+	HandlerType: (*StealthAddressServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateStealthAddress",
+			Handler:    _StealthAddress_GenerateStealthAddress_Handler,
+		},
+		{
+			MethodName: "ComputeViewTag",
+			Handler:    _StealthAddress_ComputeViewTag_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Scan",
+			Handler:       _StealthAddress_Scan_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sap.proto",
+}
@@ -0,0 +1,166 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sap-go/annfile"
+	"sap-go/delegation"
+	"sap-go/keystore"
+	"sap-go/protocol"
+	"sap-go/scanner"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate buf generate --template buf.gen.yaml --path sap.proto
+
+// scanChunk is the number of announcements Scan checks between sends, so
+// results stream while a long scan is still running.
+const scanChunk = 4096
+
+// Server implements the StealthAddress service over the announcements added
+// to it.
+type Server struct {
+	UnimplementedStealthAddressServer
+
+	mu      sync.RWMutex
+	workers int
+	records map[string][]annfile.Record
+	decoded map[string][]protocol.Decoded
+}
+
+// NewServer creates a server without announcements.
+func NewServer() *Server {
+	return &Server{
+		workers: runtime.GOMAXPROCS(0),
+		records: make(map[string][]annfile.Record),
+		decoded: make(map[string][]protocol.Decoded),
+	}
+}
+
+// Add decodes records and makes them available to Scan. Either all records
+// are added or, if one is invalid, none.
+func (s *Server) Add(records []annfile.Record) error {
+	decoded := make([]protocol.Decoded, len(records))
+	ids := make([]string, len(records))
+	for i, record := range records {
+		p, d, err := record.Decode()
+		if err != nil {
+			return fmt.Errorf("announcement %d: %w", i, err)
+		}
+		decoded[i], ids[i] = d, protocol.ID(p)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, id := range ids {
+		s.records[id] = append(s.records[id], records[i])
+		s.decoded[id] = append(s.decoded[id], decoded[i])
+	}
+	return nil
+}
+
+func lookup(name, curve string) (protocol.Protocol, error) {
+	p, err := protocol.Lookup(name, curve)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	return p, nil
+}
+
+// recipient restores the view-only recipient of c.
+func recipient(c *ViewingCredential) (protocol.Protocol, protocol.Recipient, error) {
+	if c == nil || c.MetaAddress == nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "missing viewing credential")
+	}
+	credential := &delegation.Credential{
+		Version:     delegation.Version,
+		Protocol:    c.Protocol,
+		Curve:       c.Curve,
+		MetaAddress: keystore.MetaAddress{Spend: c.MetaAddress.Spend, View: c.MetaAddress.View},
+		ViewingKey:  c.ViewingKey,
+	}
+	p, r, err := credential.Open()
+	if err != nil {
+		return nil, nil, invalidArgument(err)
+	}
+	return p, r, nil
+}
+
+// invalidArgument reports errors caused by the request's keys or points.
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func announcement(ann protocol.Announcement) *Announcement {
+	return &Announcement{Ephemeral: ann.Ephemeral, ViewTag: uint32(ann.ViewTag), Address: ann.Address[:]}
+}
+
+func (s *Server) GenerateStealthAddress(ctx context.Context, req *GenerateStealthAddressRequest) (*GenerateStealthAddressResponse, error) {
+	p, err := lookup(req.Protocol, req.Curve)
+	if err != nil {
+		return nil, err
+	}
+	if req.MetaAddress == nil {
+		return nil, status.Error(codes.InvalidArgument, "missing meta address")
+	}
+	ann, err := p.Send(protocol.MetaAddress{Spend: req.MetaAddress.Spend, View: req.MetaAddress.View})
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	return &GenerateStealthAddressResponse{Announcement: announcement(ann), StealthAddress: ann.Address[:]}, nil
+}
+
+func (s *Server) ComputeViewTag(ctx context.Context, req *ComputeViewTagRequest) (*ComputeViewTagResponse, error) {
+	p, r, err := recipient(req.Credential)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := p.DecodeEphemeral(req.Ephemeral)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	viewTag, err := p.ViewTag(r, ephemeral)
+	if err != nil {
+		return nil, invalidArgument(err)
+	}
+	return &ComputeViewTagResponse{ViewTag: uint32(viewTag)}, nil
+}
+
+func (s *Server) Scan(req *ScanRequest, stream StealthAddress_ScanServer) error {
+	p, r, err := recipient(req.Credential)
+	if err != nil {
+		return err
+	}
+	id := protocol.ID(p)
+	for start := req.Start; ; start += scanChunk {
+		if err := stream.Context().Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+		// announcements are only appended, so a chunk can be scanned unlocked
+		s.mu.RLock()
+		records, decoded := s.records[id], s.decoded[id]
+		s.mu.RUnlock()
+		if start >= uint64(len(decoded)) {
+			return nil
+		}
+		end := min(start+scanChunk, uint64(len(decoded)))
+		matches, _, err := scanner.ScanParallel(p, r, scanner.Slice(decoded[start:end]), s.workers, !req.SkipViewTags)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		for _, i := range matches {
+			index := start + uint64(i)
+			stealthPublicKey, err := p.StealthPublicKey(r, decoded[index].Ephemeral)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			result := &ScanResult{Index: index, Announcement: announcement(records[index].Announcement()), StealthPublicKey: stealthPublicKey}
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		}
+	}
+}
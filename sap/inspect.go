@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"sap-go/annfile"
+	"sap-go/codec"
+	"sap-go/erc5564"
+	"sap-go/protocol"
+	"strings"
+)
+
+func inspect(args []string) error {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	protocolName := fs.String("protocol", "", "only check a meta-address against this protocol")
+	curveName := fs.String("curve", "", "curve of -protocol")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("inspect takes exactly one value: a stealth meta-address, an announcement record or a tagged hex encoding")
+	}
+	value := strings.TrimSpace(fs.Arg(0))
+	switch {
+	case strings.HasPrefix(value, "st:"):
+		protocols := protocol.All()
+		if *protocolName != "" {
+			p, err := protocol.Lookup(*protocolName, *curveName)
+			if err != nil {
+				return err
+			}
+			protocols = []protocol.Protocol{p}
+		}
+		return inspectMetaAddress(value, protocols)
+	case strings.HasPrefix(value, "{"):
+		return inspectRecord(value)
+	default:
+		return inspectTagged(value)
+	}
+}
+
+// inspectMetaAddress prints the keys of a stealth meta-address for every
+// protocol they are valid keys of.
+func inspectMetaAddress(s string, protocols []protocol.Protocol) error {
+	chain, _, _ := strings.Cut(strings.TrimPrefix(s, "st:"), ":")
+	var valid, reasons []string
+	for _, p := range protocols {
+		meta, err := erc5564.ParseMetaAddress(p, s)
+		if err == nil {
+			// Send decodes and validates both keys
+			_, err = p.Send(meta)
+		}
+		if err != nil {
+			reasons = append(reasons, fmt.Sprintf("%s: %v", protocol.ID(p), err))
+			continue
+		}
+		id, _ := erc5564.SchemeID(p)
+		valid = append(valid,
+			fmt.Sprintf("%s (scheme %d)", protocol.ID(p), id),
+			fmt.Sprintf("  Spending public key: %s (%d bytes)", hex.EncodeToString(meta.Spend), len(meta.Spend)),
+			fmt.Sprintf("  Viewing public key:  %s (%d bytes)", hex.EncodeToString(meta.View), len(meta.View)))
+	}
+	if len(valid) == 0 {
+		return fmt.Errorf("not a valid meta-address:\n  %s", strings.Join(reasons, "\n  "))
+	}
+	fmt.Println("Stealth meta-address on chain", chain)
+	for _, line := range valid {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// inspectRecord prints and validates a JSON announcement record.
+func inspectRecord(s string) error {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.DisallowUnknownFields()
+	var record struct {
+		annfile.Record
+		// SchemeID is accepted so the output of send can be inspected.
+		SchemeID *uint64 `json:"schemeId"`
+	}
+	if err := dec.Decode(&record); err != nil {
+		return fmt.Errorf("%w: %v", annfile.ErrFormat, err)
+	}
+	p, _, err := record.Decode()
+	if err != nil {
+		return err
+	}
+	id, err := erc5564.SchemeID(p)
+	if err != nil {
+		return err
+	}
+	if record.SchemeID != nil && *record.SchemeID != id {
+		return fmt.Errorf("scheme ID %d, expected %d for %s", *record.SchemeID, id, protocol.ID(p))
+	}
+	fmt.Println("Announcement")
+	fmt.Printf("Protocol: %s (scheme %d)\n", protocol.ID(p), id)
+	fmt.Printf("Ephemeral public key: %s (%d bytes)\n", hex.EncodeToString(record.Ephemeral), len(record.Ephemeral))
+	fmt.Println("View tag:", record.ViewTag)
+	fmt.Println("Stealth address:", record.Address)
+	return nil
+}
+
+// inspectTagged prints and validates a value in the codec's tagged encoding.
+func inspectTagged(s string) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return fmt.Errorf("not a meta-address, announcement record or hex encoding: %w", err)
+	}
+	curve, kind, payload, err := codec.Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	details, err := decodeTagged(curve, kind, b)
+	if err != nil {
+		return fmt.Errorf("invalid %v on %v: %w", kind, curve, err)
+	}
+	fmt.Printf("Tagged encoding, version %d\n", codec.Version)
+	fmt.Println("Curve:", curve)
+	fmt.Println("Kind:", kind)
+	fmt.Printf("Payload: %s (%d bytes)\n", hex.EncodeToString(payload), len(payload))
+	for _, d := range details {
+		fmt.Println(d)
+	}
+	return nil
+}
+
+// decodeTagged validates a tagged value and describes it: the coordinates of
+// points, and only the properties checked for scalars and GT elements.
+func decodeTagged(curve codec.Curve, kind codec.Kind, b []byte) ([]string, error) {
+	const scalar = "Valid: canonical and non-zero"
+	const gt = "Valid: canonical, of order r and not the identity"
+	const point = "Valid: canonical, on the curve, in the prime-order subgroup and not the identity"
+	switch curve {
+	case codec.CurveSecp256k1:
+		switch kind {
+		case codec.KindScalar:
+			_, err := codec.UnmarshalSecp256k1Scalar(b)
+			return []string{scalar}, err
+		case codec.KindG1Compressed, codec.KindG1Uncompressed:
+			p, err := codec.UnmarshalSecp256k1(b)
+			return []string{"Valid: canonical and on the curve", "x: " + p.X.String(), "y: " + p.Y.String()}, err
+		}
+	case codec.CurveBN254:
+		switch kind {
+		case codec.KindScalar:
+			_, err := codec.UnmarshalBN254Scalar(b)
+			return []string{scalar}, err
+		case codec.KindG1Compressed, codec.KindG1Uncompressed:
+			p, err := codec.UnmarshalBN254G1(b)
+			return []string{point, "x: " + p.X.String(), "y: " + p.Y.String()}, err
+		case codec.KindG2Compressed, codec.KindG2Uncompressed:
+			p, err := codec.UnmarshalBN254G2(b)
+			return []string{point, "x: " + p.X.String(), "y: " + p.Y.String()}, err
+		case codec.KindGT:
+			_, err := codec.UnmarshalBN254GT(b)
+			return []string{gt}, err
+		}
+	case codec.CurveBLS12377:
+		switch kind {
+		case codec.KindScalar:
+			_, err := codec.UnmarshalBLS12377Scalar(b)
+			return []string{scalar}, err
+		case codec.KindG1Compressed, codec.KindG1Uncompressed:
+			p, err := codec.UnmarshalBLS12377G1(b)
+			return []string{point, "x: " + p.X.String(), "y: " + p.Y.String()}, err
+		case codec.KindG2Compressed, codec.KindG2Uncompressed:
+			p, err := codec.UnmarshalBLS12377G2(b)
+			return []string{point, "x: " + p.X.String(), "y: " + p.Y.String()}, err
+		case codec.KindGT:
+			_, err := codec.UnmarshalBLS12377GT(b)
+			return []string{gt}, err
+		}
+	}
+	return nil, fmt.Errorf("%w: no %v on %v", codec.ErrKind, kind, curve)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sap-go/erc5564"
+	"sap-go/keystore"
+	"sap-go/protocol"
+)
+
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	protocolName := fs.String("protocol", "ecpdksap", "protocol to generate keys for")
+	curveName := fs.String("curve", "bn254", "curve the protocol is instantiated on")
+	keystorePath := fs.String("keystore", "", "write the keys to an encrypted keystore at this path")
+	passwordFile := fs.String("password-file", "", "file holding the keystore password")
+	kdfName := fs.String("kdf", keystore.KDFScrypt, "keystore KDF: scrypt or argon2id")
+	metaPath := fs.String("meta-address", "", "also write the stealth meta-address to this file")
+	chain := fs.String("chain", "eth", "chain short name in the stealth meta-address")
+	fs.Parse(args)
+
+	if *keystorePath == "" || *passwordFile == "" {
+		return errors.New("keygen requires -keystore and -password-file")
+	}
+	p, err := protocol.Lookup(*protocolName, *curveName)
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	kdf, err := keystore.ParseKDF(*kdfName)
+	if err != nil {
+		return err
+	}
+	id, err := erc5564.SchemeID(p)
+	if err != nil {
+		return err
+	}
+	// checked up front, so a keystore is not written for a meta-address
+	// that cannot be
+	if *metaPath != "" {
+		if _, err := os.Stat(*metaPath); err == nil {
+			return fmt.Errorf("%s already exists", *metaPath)
+		}
+	}
+
+	r, err := p.GenerateRecipient()
+	if err != nil {
+		return err
+	}
+	ks, err := keystore.Encrypt(p, r, password, kdf)
+	if err != nil {
+		return err
+	}
+	if err := keystore.Save(*keystorePath, ks); err != nil {
+		return err
+	}
+	meta := erc5564.FormatMetaAddress(*chain, r.MetaAddress())
+	if *metaPath != "" {
+		if err := writeNew(*metaPath, []byte(meta+"\n")); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Protocol:", protocol.ID(p))
+	fmt.Println("Scheme ID:", id)
+	fmt.Println("Keystore:", *keystorePath)
+	fmt.Println("Meta-address:", meta)
+	return nil
+}
+
+// writeNew writes data to a file that must not exist yet.
+func writeNew(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const usage = `Usage:
+  sap send [flags] <meta-address>   pay a stealth meta-address and print the announcement
+  sap scan [flags]                  find the announcements addressed to a keystore's recipient
+  sap keygen [flags]                create a recipient with random keys in a new keystore
+  sap inspect [flags] <value>       validate and print a meta-address, announcement record
+                                    or tagged key, point or GT encoding
+
+Run "sap <command> -h" for the flags of a command.`
+
+// readPassword reads a keystore password from the first line of a file.
+func readPassword(path string) ([]byte, error) {
+	password, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	password = []byte(strings.TrimRight(string(password), "\r\n"))
+	if len(password) == 0 {
+		return nil, errors.New("empty keystore password")
+	}
+	return password, nil
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "send":
+		err = send(os.Args[2:])
+	case "scan":
+		err = scan(os.Args[2:])
+	case "keygen":
+		err = keygen(os.Args[2:])
+	case "inspect":
+		err = inspect(os.Args[2:])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"sap-go/annfile"
+	"sap-go/indexer"
+	"sap-go/keystore"
+	"sap-go/protocol"
+	"sap-go/scanner"
+)
+
+// found is an announcement addressed to the recipient and where it was read.
+type found struct {
+	where string
+	ann   protocol.Decoded
+}
+
+func scan(args []string) error {
+	fs := flag.NewFlagSet("scan", flag.ExitOnError)
+	keystorePath := fs.String("keystore", "", "keystore of the recipient")
+	passwordFile := fs.String("password-file", "", "file holding the keystore password")
+	announcements := fs.String("announcements", "", "JSON Lines announcement file to scan")
+	db := fs.String("db", "", "announcement index database to scan instead")
+	cursor := fs.String("cursor", "", "with -db, resume from and advance the index cursor of this name")
+	workers := fs.Int("workers", runtime.GOMAXPROCS(0), "number of scanning goroutines")
+	noViewTags := fs.Bool("no-view-tags", false, "check every announcement in full instead of filtering by view tag")
+	fs.Parse(args)
+
+	if *keystorePath == "" || *passwordFile == "" {
+		return errors.New("scan requires -keystore and -password-file")
+	}
+	if (*announcements == "") == (*db == "") {
+		return errors.New("scan requires one of -announcements and -db")
+	}
+	if *cursor != "" && *db == "" {
+		return errors.New("-cursor requires -db")
+	}
+	ks, err := keystore.Load(*keystorePath)
+	if err != nil {
+		return err
+	}
+	password, err := readPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	p, r, err := ks.Decrypt(password)
+	if err != nil {
+		return err
+	}
+
+	var matches []found
+	var stats scanner.Stats
+	var invalid int
+	if *announcements != "" {
+		matches, stats, invalid, err = scanFile(p, r, *announcements, *workers, !*noViewTags)
+	} else {
+		matches, stats, invalid, err = scanIndex(p, r, *db, *cursor, *workers, !*noViewTags)
+	}
+	if err != nil {
+		return err
+	}
+	if invalid > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d invalid announcements\n", invalid)
+	}
+
+	fmt.Println("Protocol:", protocol.ID(p))
+	fmt.Printf("Scanned %d announcements, %d view tag hits, %d found\n", stats.Scanned, stats.ViewTagHits, len(matches))
+	if ks.ViewOnly && len(matches) > 0 {
+		fmt.Println("View-only keystore: stealth private keys are not derived")
+	}
+	for _, m := range matches {
+		if ks.ViewOnly {
+			fmt.Printf("%s: %s\n", m.where, m.ann.Address)
+			continue
+		}
+		privateKey, err := p.Derive(r, m.ann.Ephemeral)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.where, err)
+		}
+		publicKey, err := p.PublicKey(privateKey)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.where, err)
+		}
+		if protocol.AddressFromPublicKey(publicKey) != m.ann.Address {
+			return fmt.Errorf("%s: derived key does not match the stealth address", m.where)
+		}
+		fmt.Printf("%s: %s key %s\n", m.where, m.ann.Address, hex.EncodeToString(privateKey))
+	}
+	return nil
+}
+
+// scanFile scans the announcements of p in a JSON Lines file. Announcements of
+// other protocols are ignored. Anyone can publish announcements, so lines
+// that are not valid records and announcements of p that do not decode are
+// skipped and counted instead of failing the scan.
+func scanFile(p protocol.Protocol, r protocol.Recipient, path string, workers int, viewTags bool) ([]found, scanner.Stats, int, error) {
+	records, lines, invalid, err := annfile.LoadLenient(path)
+	if err != nil {
+		return nil, scanner.Stats{}, invalid, err
+	}
+	var decoded []protocol.Decoded
+	var decodedLines []int
+	for i, record := range records {
+		if record.Protocol != p.Name() || record.Curve != p.Curve() {
+			continue
+		}
+		d, err := protocol.Decode(p, record.Announcement())
+		if err != nil {
+			invalid++
+			continue
+		}
+		decoded, decodedLines = append(decoded, d), append(decodedLines, lines[i])
+	}
+	indices, stats, err := scanner.ScanParallel(p, r, scanner.Slice(decoded), workers, viewTags)
+	if err != nil {
+		return nil, stats, invalid, err
+	}
+	matches := make([]found, len(indices))
+	for i, index := range indices {
+		matches[i] = found{fmt.Sprintf("line %d", decodedLines[index]), decoded[index]}
+	}
+	return matches, stats, invalid, nil
+}
+
+// scanIndex scans the announcements of p in an index, all of them or, with a
+// cursor, those stored since the cursor's last scan. Entries of p that do not
+// decode are skipped and counted; the index validates entries when they are
+// stored, so a cursor scan finds none.
+func scanIndex(p protocol.Protocol, r protocol.Recipient, path, cursor string, workers int, viewTags bool) ([]found, scanner.Stats, int, error) {
+	ix, err := indexer.Open(path)
+	if err != nil {
+		return nil, scanner.Stats{}, 0, err
+	}
+	defer ix.Close()
+
+	var entries []indexer.Entry
+	var stats scanner.Stats
+	var invalid int
+	if cursor != "" {
+		entries, stats, err = ix.Scan(cursor, p, r, workers, viewTags)
+	} else {
+		var all []indexer.Entry
+		var decoded []protocol.Decoded
+		err = ix.From(indexer.Position{}, 0, func(e indexer.Entry) error {
+			if e.Protocol != p.Name() || e.Curve != p.Curve() {
+				return nil
+			}
+			d, err := protocol.Decode(p, e.Announcement())
+			if err != nil {
+				invalid++
+				return nil
+			}
+			all, decoded = append(all, e), append(decoded, d)
+			return nil
+		})
+		if err != nil {
+			return nil, stats, invalid, err
+		}
+		var indices []int
+		indices, stats, err = scanner.ScanParallel(p, r, scanner.Slice(decoded), workers, viewTags)
+		for _, i := range indices {
+			entries = append(entries, all[i])
+		}
+	}
+	if err != nil {
+		return nil, stats, invalid, err
+	}
+
+	matches := make([]found, len(entries))
+	for i, e := range entries {
+		d, err := protocol.Decode(p, e.Announcement())
+		if err != nil {
+			return nil, stats, invalid, err
+		}
+		matches[i] = found{fmt.Sprintf("block %d log %d (tx %s)", e.Block, e.LogIndex, e.TxHash), d}
+	}
+	return matches, stats, invalid, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sap-go/annfile"
+	"sap-go/erc5564"
+	"sap-go/protocol"
+)
+
+// sendOutput is the JSON output of send: the announcement record, whose
+// address is the stealth address, with its ERC-5564 scheme ID.
+type sendOutput struct {
+	annfile.Record
+	SchemeID uint64 `json:"schemeId"`
+}
+
+func send(args []string) error {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	protocolName := fs.String("protocol", "ecpdksap", "protocol of the meta-address")
+	curveName := fs.String("curve", "bn254", "curve the protocol is instantiated on")
+	format := fs.String("format", "json", "output format: json, or calldata for the ERC5564Announcer's announce")
+	announcements := fs.String("announcements", "", "append the announcement to this JSON Lines file")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return errors.New("send takes exactly one meta-address, st:<chain>:0x<keys> or 0x<keys>")
+	}
+	if *format != "json" && *format != "calldata" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	p, err := protocol.Lookup(*protocolName, *curveName)
+	if err != nil {
+		return err
+	}
+	meta, err := erc5564.ParseMetaAddress(p, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ann, err := p.Send(meta)
+	if err != nil {
+		return err
+	}
+	id, err := erc5564.SchemeID(p)
+	if err != nil {
+		return err
+	}
+
+	// the announcement is appended before it is printed, so no stealth
+	// address is paid whose announcement was not recorded
+	record := annfile.NewRecord(p, ann)
+	if *announcements != "" {
+		if err := annfile.Append(*announcements, record); err != nil {
+			return err
+		}
+	}
+	if *format == "calldata" {
+		calldata, err := erc5564.EncodeAnnounce(p, ann)
+		if err != nil {
+			return err
+		}
+		fmt.Println("0x" + hex.EncodeToString(calldata))
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sendOutput{Record: record, SchemeID: id})
+}
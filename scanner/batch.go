@@ -0,0 +1,81 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"sap-go/protocol"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ScanBatch scans src once for all of recipients on the given number of
+// goroutines. Each announcement is decoded, validated and prepared once and
+// then checked against every recipient, so the work shared between them is
+// not repeated. matches[j] holds the sorted indices addressed to
+// recipients[j]; stats count one scan per announcement and recipient.
+func ScanBatch(p protocol.Protocol, recipients []protocol.Recipient, src Source, workers int, viewTags bool) ([][]int, Stats, error) {
+	workers = max(workers, 1)
+	var next atomic.Int64
+	var failed atomic.Bool
+	workerMatches := make([][][]int, workers)
+	workerStats := make([]Stats, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			workerMatches[w] = make([][]int, len(recipients))
+			for !failed.Load() {
+				start := int(next.Add(batchSize)) - batchSize
+				if start >= src.Len() {
+					return
+				}
+				for i := start; i < min(start+batchSize, src.Len()); i++ {
+					if err := checkBatch(p, recipients, src, i, viewTags, workerMatches[w], &workerStats[w]); err != nil {
+						errs[w] = fmt.Errorf("announcement %d: %w", i, err)
+						failed.Store(true)
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	matches := make([][]int, len(recipients))
+	var stats Stats
+	for w := range workerMatches {
+		for j := range workerMatches[w] {
+			matches[j] = append(matches[j], workerMatches[w][j]...)
+		}
+		stats.add(workerStats[w])
+	}
+	for j := range matches {
+		sort.Ints(matches[j])
+	}
+	return matches, stats, errors.Join(errs...)
+}
+
+// checkBatch prepares announcement i of src and checks it against every recipient.
+func checkBatch(p protocol.Protocol, recipients []protocol.Recipient, src Source, i int, viewTags bool, matches [][]int, stats *Stats) error {
+	ann, err := src.Decoded(i)
+	if err != nil {
+		return err
+	}
+	if ann.Ephemeral, err = p.Prepare(ann.Ephemeral); err != nil {
+		return err
+	}
+	for j, r := range recipients {
+		match, err := check(p, r, &ann, viewTags, stats)
+		if err != nil {
+			return fmt.Errorf("recipient %d: %w", j, err)
+		}
+		if match {
+			matches[j] = append(matches[j], i)
+		}
+	}
+	return nil
+}
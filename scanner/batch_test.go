@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"reflect"
+	"sap-go/protocol"
+	"testing"
+)
+
+// TestScanBatch checks that ScanBatch finds, for every recipient, the same
+// indices and does the same work as ScanParallel run once per recipient, for
+// every protocol, with and without view tags. ScanBatch prepares each
+// announcement before checking it, which for ecpsksap exercises the
+// precomputed pairing of Prepare.
+func TestScanBatch(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testScanBatch(t, p) })
+	}
+}
+
+func testScanBatch(t *testing.T, p protocol.Protocol) {
+	// More than one batch of announcements, so several workers take part.
+	const n, workers = batchSize + 44, 4
+	recipients := make([]protocol.Recipient, 3)
+	for j := range recipients {
+		r, err := p.GenerateRecipient()
+		if err != nil {
+			t.Fatalf("GenerateRecipient: %v", err)
+		}
+		recipients[j] = r
+	}
+	// Recipient j is paid at the indices i with i%50 == j, and the last
+	// recipient is never paid.
+	src := make(Slice, n)
+	expected := make([][]int, len(recipients))
+	for i := range src {
+		var ann protocol.Announcement
+		var err error
+		if j := i % 50; j < len(recipients)-1 {
+			ann, err = p.Send(recipients[j].MetaAddress())
+			expected[j] = append(expected[j], i)
+		} else {
+			ann, err = p.RandomAnnouncement()
+		}
+		if err != nil {
+			t.Fatalf("announcement %d: %v", i, err)
+		}
+		if src[i], err = protocol.Decode(p, ann); err != nil {
+			t.Fatalf("Decode %d: %v", i, err)
+		}
+	}
+
+	for _, viewTags := range []bool{true, false} {
+		matches, stats, err := ScanBatch(p, recipients, src, workers, viewTags)
+		if err != nil {
+			t.Fatalf("ScanBatch(viewTags=%v): %v", viewTags, err)
+		}
+		var total Stats
+		for j, r := range recipients {
+			indices, s, err := ScanParallel(p, r, src, workers, viewTags)
+			if err != nil {
+				t.Fatalf("ScanParallel(viewTags=%v): %v", viewTags, err)
+			}
+			total.add(s)
+			if !reflect.DeepEqual(indices, expected[j]) {
+				t.Errorf("ScanParallel(viewTags=%v): recipient %d: got %v, expected %v", viewTags, j, indices, expected[j])
+			}
+			if !reflect.DeepEqual(matches[j], indices) {
+				t.Errorf("ScanBatch(viewTags=%v): recipient %d: got %v, ScanParallel found %v", viewTags, j, matches[j], indices)
+			}
+		}
+		if stats != total {
+			t.Errorf("ScanBatch(viewTags=%v): got stats %+v, ScanParallel did %+v", viewTags, stats, total)
+		}
+		if !viewTags && stats.ViewTagHits != 0 {
+			t.Errorf("ScanBatch(viewTags=false): got %d view tag hits", stats.ViewTagHits)
+		}
+	}
+}
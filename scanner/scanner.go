@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"sap-go/protocol"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// batchSize is the number of announcements a ScanParallel worker claims at a time.
+const batchSize = 256
+
+// Stats counts the work done by a scan.
+type Stats struct {
+	Scanned     int
+	ViewTagHits int
+	Matches     int
+}
+
+func (s *Stats) add(o Stats) {
+	s.Scanned += o.Scanned
+	s.ViewTagHits += o.ViewTagHits
+	s.Matches += o.Matches
+}
+
+// Source provides announcements by index, decoding them on demand.
+type Source interface {
+	Len() int
+	Decoded(i int) (protocol.Decoded, error)
+}
+
+// Slice is a Source of announcements decoded in memory.
+type Slice []protocol.Decoded
+
+func (s Slice) Len() int { return len(s) }
+
+func (s Slice) Decoded(i int) (protocol.Decoded, error) { return s[i], nil }
+
+// check scans a single announcement and updates stats.
+func check(p protocol.Protocol, r protocol.Recipient, ann *protocol.Decoded, viewTags bool, stats *Stats) (bool, error) {
+	stats.Scanned++
+	if viewTags {
+		viewTag, err := p.ViewTag(r, ann.Ephemeral)
+		if err != nil {
+			return false, err
+		}
+		if viewTag != ann.ViewTag {
+			return false, nil
+		}
+		stats.ViewTagHits++
+	}
+
+	stealthPublicKey, err := p.StealthPublicKey(r, ann.Ephemeral)
+	if err != nil {
+		return false, err
+	}
+	if protocol.AddressFromPublicKey(stealthPublicKey) != ann.Address {
+		return false, nil
+	}
+	stats.Matches++
+	return true, nil
+}
+
+// Scan returns the indices of the announcements addressed to r. With
+// viewTags set, the stealth public key is only derived for announcements
+// whose view tag matches.
+func Scan(p protocol.Protocol, r protocol.Recipient, announcements []protocol.Decoded, viewTags bool) ([]int, Stats, error) {
+	var matches []int
+	var stats Stats
+	for i := range announcements {
+		match, err := check(p, r, &announcements[i], viewTags, &stats)
+		if err != nil {
+			return matches, stats, err
+		}
+		if match {
+			matches = append(matches, i)
+		}
+	}
+	return matches, stats, nil
+}
+
+// ScanParallel is Scan over src on the given number of goroutines. Workers
+// claim batches of announcements and decode them as they go, so src may
+// decode lazily. The returned indices are sorted.
+func ScanParallel(p protocol.Protocol, r protocol.Recipient, src Source, workers int, viewTags bool) ([]int, Stats, error) {
+	workers = max(workers, 1)
+	var next atomic.Int64
+	var failed atomic.Bool
+	workerMatches := make([][]int, workers)
+	workerStats := make([]Stats, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for !failed.Load() {
+				start := int(next.Add(batchSize)) - batchSize
+				if start >= src.Len() {
+					return
+				}
+				for i := start; i < min(start+batchSize, src.Len()); i++ {
+					ann, err := src.Decoded(i)
+					if err == nil {
+						var match bool
+						match, err = check(p, r, &ann, viewTags, &workerStats[w])
+						if match {
+							workerMatches[w] = append(workerMatches[w], i)
+						}
+					}
+					if err != nil {
+						errs[w] = fmt.Errorf("announcement %d: %w", i, err)
+						failed.Store(true)
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var matches []int
+	var stats Stats
+	for w := range workerMatches {
+		matches = append(matches, workerMatches[w]...)
+		stats.add(workerStats[w])
+	}
+	sort.Ints(matches)
+	return matches, stats, errors.Join(errs...)
+}
+
+// Decode decodes the ephemeral keys of announcements so they can be scanned
+// repeatedly without paying for point decompression.
+func Decode(p protocol.Protocol, announcements []protocol.Announcement) ([]protocol.Decoded, error) {
+	decoded := make([]protocol.Decoded, len(announcements))
+	for i, ann := range announcements {
+		d, err := protocol.Decode(p, ann)
+		if err != nil {
+			return nil, fmt.Errorf("announcement %d: %w", i, err)
+		}
+		decoded[i] = d
+	}
+	return decoded, nil
+}
@@ -0,0 +1,10 @@
+package main
+
+import (
+	"sap-go/harness"
+	"sap-go/protocol"
+)
+
+func main() {
+	harness.Main(protocol.DKSAPSecp256k1())
+}
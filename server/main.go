@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sap-go/annfile"
+	"sap-go/service"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	announcements := flag.String("announcements", "", "JSON Lines announcement file to load at startup")
+	flag.Parse()
+
+	server := service.New()
+	if *announcements != "" {
+		records, err := annfile.Load(*announcements)
+		if err != nil {
+			fmt.Println("Error reading announcements:", err)
+			os.Exit(1)
+		}
+		if _, err := server.Add(records); err != nil {
+			fmt.Println("Error loading announcements:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Loaded %d announcements from %s\n", len(records), *announcements)
+	}
+
+	fmt.Println("Listening on", *addr)
+	httpServer := &http.Server{Addr: *addr, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Println("Error serving:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,489 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sap-go/annfile"
+	"sap-go/delegation"
+	"sap-go/keystore"
+	"sap-go/protocol"
+	"sap-go/scanner"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultLimit is the page size of a matches request without a limit.
+	DefaultLimit = 100
+	// MaxLimit is the largest page size a matches request may ask for.
+	MaxLimit = 1000
+
+	maxRequestBody = 1 << 20
+	maxIngestBody  = 256 << 20
+)
+
+var (
+	// ErrUnknownRecipient is returned for recipient IDs that were never registered.
+	ErrUnknownRecipient = errors.New("unknown recipient")
+	// ErrToken is returned when a request does not carry the recipient's token.
+	ErrToken = errors.New("missing or wrong recipient token")
+)
+
+// Match is an announcement found for a recipient. Index is its position in
+// the server's log of announcements of the recipient's protocol.
+type Match struct {
+	Index int `json:"index"`
+	annfile.Record
+	StealthPublicKey keystore.Bytes `json:"stealthPublicKey"`
+}
+
+// Page is one page of a recipient's matches. Next is the cursor of the
+// following page, or nil on the last page.
+type Page struct {
+	Matches []Match `json:"matches"`
+	Next    *int    `json:"next,omitempty"`
+}
+
+// Registration describes a registered recipient. ID and Token are random;
+// the token must be presented to read the recipient's matches.
+type Registration struct {
+	ID          string               `json:"id"`
+	Token       string               `json:"token"`
+	Protocol    string               `json:"protocol"`
+	Curve       string               `json:"curve"`
+	MetaAddress keystore.MetaAddress `json:"metaAddress"`
+	Matches     int                  `json:"matches"`
+}
+
+// Accepted reports how many announcements a submission added.
+type Accepted struct {
+	Accepted int `json:"accepted"`
+}
+
+// recipient is a registered view-only recipient and the announcements found for it.
+type recipient struct {
+	id      string
+	token   string
+	p       protocol.Protocol
+	r       protocol.Recipient
+	matches []int
+}
+
+// announcementLog holds the announcements of one protocol and the
+// recipients registered for it.
+type announcementLog struct {
+	p          protocol.Protocol
+	records    []annfile.Record
+	decoded    []protocol.Decoded
+	recipients []*recipient
+}
+
+// Server scans announcements for recipients registered with a viewing
+// credential. Announcements are scanned for every registered recipient when
+// they are submitted, with one batch scan per protocol, and a new recipient
+// is scanned against the announcements already submitted. Scans run outside
+// the lock; logs and recipient lists only grow, so a scan covers a prefix
+// and the rest is scanned before its results are merged.
+type Server struct {
+	mu         sync.RWMutex
+	workers    int
+	logs       map[string]*announcementLog
+	recipients map[string]*recipient
+	// registered maps the key derived from a recipient's meta address to its
+	// registration, so registering a credential twice returns the same one.
+	registered map[string]*recipient
+	mux        *http.ServeMux
+}
+
+// New creates a server without announcements or recipients. Its HTTP API is:
+//
+//	POST /recipients                     register a viewing credential
+//	GET  /recipients/{id}                describe a registered recipient
+//	GET  /recipients/{id}/matches        page through matches (?cursor=&limit=)
+//	POST /announcements                  submit a JSON array of announcement records
+//	POST /announcements/ingest           ingest a JSON Lines announcement file
+//
+// The recipient endpoints require the token returned at registration as
+// "Authorization: Bearer <token>".
+func New() *Server {
+	s := &Server{
+		workers:    runtime.GOMAXPROCS(0),
+		logs:       make(map[string]*announcementLog),
+		recipients: make(map[string]*recipient),
+		registered: make(map[string]*recipient),
+		mux:        http.NewServeMux(),
+	}
+	s.mux.HandleFunc("/recipients", s.handleRegister)
+	s.mux.HandleFunc("/recipients/", s.handleRecipient)
+	s.mux.HandleFunc("/announcements", s.handleSubmit)
+	s.mux.HandleFunc("/announcements/ingest", s.handleIngest)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// registrationKey derives a key from the protocol and meta address to detect
+// repeated registrations. Anyone knowing the meta address can compute it, so
+// it is never handed out.
+func registrationKey(p protocol.Protocol, meta protocol.MetaAddress) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s|%x|%x", protocol.ID(p), meta.Spend, meta.View)
+	return hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// randomHex returns n random bytes in hex.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Register adds the recipient of c, scanning the announcements submitted so
+// far. It reports whether the recipient is new; registering a credential
+// again returns the existing ID and token.
+func (s *Server) Register(c *delegation.Credential) (Registration, bool, error) {
+	p, r, err := c.Open()
+	if err != nil {
+		return Registration{}, false, err
+	}
+	key := registrationKey(p, r.MetaAddress())
+	var matches []int
+	for scanned := 0; ; {
+		s.mu.RLock()
+		existing := s.registered[key]
+		var decoded []protocol.Decoded
+		if log := s.logs[protocol.ID(p)]; log != nil {
+			decoded = log.decoded
+		}
+		s.mu.RUnlock()
+		if existing != nil {
+			return existing.registration(), false, nil
+		}
+
+		found, _, err := scanner.ScanParallel(p, r, scanner.Slice(decoded[scanned:]), s.workers, true)
+		if err != nil {
+			return Registration{}, false, err
+		}
+		for _, i := range found {
+			matches = append(matches, scanned+i)
+		}
+		scanned = len(decoded)
+
+		s.mu.Lock()
+		if existing := s.registered[key]; existing != nil {
+			s.mu.Unlock()
+			return existing.registration(), false, nil
+		}
+		log := s.log(p)
+		if len(log.decoded) > scanned {
+			// announcements were added during the scan
+			s.mu.Unlock()
+			continue
+		}
+		rec := &recipient{p: p, r: r, matches: matches}
+		if rec.id, err = randomHex(16); err == nil {
+			rec.token, err = randomHex(32)
+		}
+		if err != nil {
+			s.mu.Unlock()
+			return Registration{}, false, err
+		}
+		s.recipients[rec.id] = rec
+		s.registered[key] = rec
+		log.recipients = append(log.recipients, rec)
+		s.mu.Unlock()
+		return rec.registration(), true, nil
+	}
+}
+
+func (rec *recipient) registration() Registration {
+	meta := rec.r.MetaAddress()
+	return Registration{
+		ID:          rec.id,
+		Token:       rec.token,
+		Protocol:    rec.p.Name(),
+		Curve:       rec.p.Curve(),
+		MetaAddress: keystore.MetaAddress{Spend: meta.Spend, View: meta.View},
+		Matches:     len(rec.matches),
+	}
+}
+
+// log returns the announcement log of p, creating it. s.mu must be held.
+func (s *Server) log(p protocol.Protocol) *announcementLog {
+	id := protocol.ID(p)
+	if s.logs[id] == nil {
+		s.logs[id] = &announcementLog{p: p}
+	}
+	return s.logs[id]
+}
+
+// Add decodes records and scans them for every registered recipient of
+// their protocol. Either all records are added or, if one is invalid, none.
+func (s *Server) Add(records []annfile.Record) (int, error) {
+	type batch struct {
+		p       protocol.Protocol
+		records []annfile.Record
+		decoded []protocol.Decoded
+		// matches holds the matches of the first len(matches) recipients of
+		// the protocol's log.
+		matches [][]int
+	}
+	batches := make(map[string]*batch)
+	for i, record := range records {
+		p, decoded, err := record.Decode()
+		if err != nil {
+			return 0, fmt.Errorf("announcement %d: %w", i, err)
+		}
+		id := protocol.ID(p)
+		if batches[id] == nil {
+			batches[id] = &batch{p: p}
+		}
+		batches[id].records = append(batches[id].records, record)
+		batches[id].decoded = append(batches[id].decoded, decoded)
+	}
+
+	for {
+		recipients := make(map[string][]*recipient)
+		s.mu.RLock()
+		for id := range batches {
+			if log := s.logs[id]; log != nil {
+				recipients[id] = log.recipients
+			}
+		}
+		s.mu.RUnlock()
+
+		for id, b := range batches {
+			fresh := recipients[id][len(b.matches):]
+			if len(fresh) == 0 {
+				continue
+			}
+			rs := make([]protocol.Recipient, len(fresh))
+			for j, rec := range fresh {
+				rs[j] = rec.r
+			}
+			matches, _, err := scanner.ScanBatch(b.p, rs, scanner.Slice(b.decoded), s.workers, true)
+			if err != nil {
+				return 0, err
+			}
+			b.matches = append(b.matches, matches...)
+		}
+
+		s.mu.Lock()
+		complete := true
+		for _, b := range batches {
+			// recipients may have registered during the scan
+			complete = complete && len(s.log(b.p).recipients) == len(b.matches)
+		}
+		if !complete {
+			s.mu.Unlock()
+			continue
+		}
+		for _, b := range batches {
+			log := s.log(b.p)
+			offset := len(log.decoded)
+			for j, rec := range log.recipients {
+				for _, i := range b.matches[j] {
+					rec.matches = append(rec.matches, offset+i)
+				}
+			}
+			log.records = append(log.records, b.records...)
+			log.decoded = append(log.decoded, b.decoded...)
+		}
+		s.mu.Unlock()
+		return len(records), nil
+	}
+}
+
+// lookup returns the recipient id if token is its token. s.mu must be held.
+func (s *Server) lookup(id, token string) (*recipient, error) {
+	rec, ok := s.recipients[id]
+	if !ok {
+		return nil, ErrUnknownRecipient
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(rec.token)) != 1 {
+		return nil, ErrToken
+	}
+	return rec, nil
+}
+
+// Recipient describes recipient id, given its token.
+func (s *Server) Recipient(id, token string) (Registration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, err := s.lookup(id, token)
+	if err != nil {
+		return Registration{}, err
+	}
+	return rec.registration(), nil
+}
+
+// Matches returns up to limit matches of recipient id from cursor on, given
+// its token.
+func (s *Server) Matches(id, token string, cursor, limit int) (Page, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, err := s.lookup(id, token)
+	if err != nil {
+		return Page{}, err
+	}
+	log := s.logs[protocol.ID(rec.p)]
+	page := Page{Matches: []Match{}}
+	start := min(cursor, len(rec.matches))
+	end := min(start+limit, len(rec.matches))
+	for _, i := range rec.matches[start:end] {
+		stealthPublicKey, err := rec.p.StealthPublicKey(rec.r, log.decoded[i].Ephemeral)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Matches = append(page.Matches, Match{Index: i, Record: log.records[i], StealthPublicKey: stealthPublicKey})
+	}
+	if end < len(rec.matches) {
+		page.Next = &end
+	}
+	return page, nil
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	c, err := delegation.Read(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	registration, created, err := s.Register(c)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, registration)
+}
+
+func (s *Server) handleRecipient(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use GET"))
+		return
+	}
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/recipients/"), "/")
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	switch sub {
+	case "":
+		registration, err := s.Recipient(id, token)
+		if err != nil {
+			writeRecipientError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, registration)
+	case "matches":
+		cursor, err := queryInt(r, "cursor", 0, -1)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		limit, err := queryInt(r, "limit", DefaultLimit, MaxLimit)
+		if err != nil || limit == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", MaxLimit))
+			return
+		}
+		page, err := s.Matches(id, token, cursor, limit)
+		if err != nil {
+			writeRecipientError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, page)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// writeRecipientError maps the errors of a recipient lookup to a status.
+func writeRecipientError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUnknownRecipient):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, ErrToken):
+		writeError(w, http.StatusUnauthorized, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+// queryInt parses a non-negative query parameter no larger than max, when max is not negative.
+func queryInt(r *http.Request, name string, def, max int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || (max >= 0 && n > max) {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return n, nil
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	var records []annfile.Record
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&records); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("%w: %v", annfile.ErrFormat, err))
+		return
+	}
+	s.add(w, records)
+}
+
+func (s *Server) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST"))
+		return
+	}
+	records, err := annfile.Read(http.MaxBytesReader(w, r.Body, maxIngestBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.add(w, records)
+}
+
+func (s *Server) add(w http.ResponseWriter, records []annfile.Record) {
+	n, err := s.Add(records)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Accepted{Accepted: n})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
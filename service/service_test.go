@@ -0,0 +1,317 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sap-go/annfile"
+	"sap-go/delegation"
+	"sap-go/protocol"
+	"sync"
+	"testing"
+)
+
+// client calls a test server and decodes its JSON responses. Requests carry
+// token as a bearer token when it is set.
+type client struct {
+	url   string
+	token string
+}
+
+// as returns the client presenting token.
+func (c client) as(token string) client {
+	c.token = token
+	return c
+}
+
+func (c client) do(method, path string, body []byte, status int, out any) error {
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != status {
+		return fmt.Errorf("%s %s: got status %d, expected %d: %s", method, path, resp.StatusCode, status, b)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
+
+// matches pages through all matches of id, limit at a time.
+func (c client) matches(id string, limit int) ([]Match, error) {
+	var all []Match
+	for cursor := 0; ; {
+		var page Page
+		if err := c.do(http.MethodGet, fmt.Sprintf("/recipients/%s/matches?cursor=%d&limit=%d", id, cursor, limit), nil, http.StatusOK, &page); err != nil {
+			return nil, err
+		}
+		all = append(all, page.Matches...)
+		if page.Next == nil {
+			return all, nil
+		}
+		if len(page.Matches) != limit {
+			return nil, fmt.Errorf("page at %d has %d matches, expected %d", cursor, len(page.Matches), limit)
+		}
+		cursor = *page.Next
+	}
+}
+
+// TestAPI runs the HTTP API end to end against an httptest server. It
+// writes a local announcement file mixing payments to two recipients with
+// random announcements of two protocols, ingests it, registers the
+// recipients before and after ingestion, submits a further announcement and
+// pages through the matches with each recipient's token. It also checks that
+// malformed or unauthenticated requests are rejected without changing the
+// server's state.
+func TestAPI(t *testing.T) {
+	p, other := protocol.ECPDKSAPBN254(), protocol.DKSAPSecp256k1()
+	alice, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := p.GenerateRecipient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice is paid at 2, 5 and 9, Bob at 7; the rest is noise.
+	payees := map[int]protocol.Recipient{2: alice, 5: alice, 7: bob, 9: alice}
+	var records []annfile.Record
+	for i := 0; i < 12; i++ {
+		var ann protocol.Announcement
+		if r, ok := payees[i]; ok {
+			ann, err = p.Send(r.MetaAddress())
+		} else {
+			ann, err = p.RandomAnnouncement()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, annfile.NewRecord(p, ann))
+		if i%4 == 0 {
+			ann, err := other.RandomAnnouncement()
+			if err != nil {
+				t.Fatal(err)
+			}
+			records = append(records, annfile.NewRecord(other, ann))
+		}
+	}
+	path := filepath.Join(t.TempDir(), "announcements.jsonl")
+	if err := annfile.Append(path, records...); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(New())
+	defer server.Close()
+	c := client{url: server.URL}
+
+	credential := func(r protocol.Recipient) []byte {
+		var buf bytes.Buffer
+		delegation.Write(&buf, delegation.Export(p, r))
+		return buf.Bytes()
+	}
+	var aliceReg, bobReg Registration
+	if err := c.do(http.MethodPost, "/recipients", credential(alice), http.StatusCreated, &aliceReg); err != nil {
+		t.Fatal(err)
+	}
+	var again Registration
+	if err := c.do(http.MethodPost, "/recipients", credential(alice), http.StatusOK, &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != aliceReg.ID || again.Token != aliceReg.Token {
+		t.Fatalf("registering twice: got ID %s, then %s", aliceReg.ID, again.ID)
+	}
+	if aliceReg.ID == registrationKey(p, alice.MetaAddress()) || len(aliceReg.Token) != 64 {
+		t.Fatalf("registration ID %s or token %q derived from the meta address", aliceReg.ID, aliceReg.Token)
+	}
+
+	file, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var accepted Accepted
+	if err := c.do(http.MethodPost, "/announcements/ingest", file, http.StatusOK, &accepted); err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Accepted != len(records) {
+		t.Fatalf("ingest: accepted %d of %d announcements", accepted.Accepted, len(records))
+	}
+	// Bob registers after ingestion and is scanned against the log.
+	if err := c.do(http.MethodPost, "/recipients", credential(bob), http.StatusCreated, &bobReg); err != nil {
+		t.Fatal(err)
+	}
+
+	ann, err := p.Send(alice.MetaAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	submitted, _ := json.Marshal([]annfile.Record{annfile.NewRecord(p, ann)})
+	if err := c.do(http.MethodPost, "/announcements", submitted, http.StatusOK, &accepted); err != nil {
+		t.Fatal(err)
+	}
+
+	// Indices count announcements of p only: the log holds the 12 of the file, then the submitted one.
+	for _, want := range []struct {
+		name    string
+		reg     Registration
+		r       protocol.Recipient
+		indices []int
+	}{
+		{"alice", aliceReg, alice, []int{2, 5, 9, 12}},
+		{"bob", bobReg, bob, []int{7}},
+	} {
+		got, err := c.as(want.reg.Token).matches(want.reg.ID, 2)
+		if err != nil {
+			t.Fatalf("%s: %v", want.name, err)
+		}
+		var indices []int
+		for _, m := range got {
+			indices = append(indices, m.Index)
+			if protocol.AddressFromPublicKey(m.StealthPublicKey) != m.Address {
+				t.Fatalf("%s: match %d: stealth public key does not hash to the address", want.name, m.Index)
+			}
+			decoded, err := protocol.Decode(p, m.Announcement())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, err := p.Check(want.r, decoded); err != nil || !ok {
+				t.Fatalf("%s: match %d is not addressed to the recipient (err: %v)", want.name, m.Index, err)
+			}
+		}
+		if !reflect.DeepEqual(indices, want.indices) {
+			t.Fatalf("%s: got matches %v, expected %v", want.name, indices, want.indices)
+		}
+		var reg Registration
+		if err := c.as(want.reg.Token).do(http.MethodGet, "/recipients/"+want.reg.ID, nil, http.StatusOK, &reg); err != nil {
+			t.Fatal(err)
+		}
+		if reg.Matches != len(want.indices) {
+			t.Fatalf("%s: registration reports %d matches", want.name, reg.Matches)
+		}
+	}
+
+	// A batch with one invalid ephemeral key is rejected as a whole.
+	invalid := annfile.NewRecord(p, ann)
+	invalid.Ephemeral = bytes.Clone(invalid.Ephemeral)
+	invalid.Ephemeral[len(invalid.Ephemeral)-1] ^= 1
+	rejected, _ := json.Marshal([]annfile.Record{annfile.NewRecord(p, ann), invalid})
+	tampered := delegation.Export(p, alice)
+	tampered.ViewingKey = bob.ViewingKey()
+	var tamperedBody bytes.Buffer
+	delegation.Write(&tamperedBody, tampered)
+	for _, bad := range []struct {
+		method, path string
+		token        string
+		body         []byte
+		status       int
+	}{
+		{http.MethodPost, "/announcements", "", rejected, http.StatusBadRequest},
+		{http.MethodPost, "/announcements", "", []byte("{"), http.StatusBadRequest},
+		{http.MethodPost, "/announcements/ingest", "", []byte("not json\n"), http.StatusBadRequest},
+		{http.MethodPost, "/recipients", "", tamperedBody.Bytes(), http.StatusBadRequest},
+		{http.MethodGet, "/recipients", "", nil, http.StatusMethodNotAllowed},
+		{http.MethodGet, "/recipients/0000/matches", aliceReg.Token, nil, http.StatusNotFound},
+		{http.MethodGet, "/recipients/" + aliceReg.ID + "/matches", "", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/recipients/" + aliceReg.ID + "/matches", bobReg.Token, nil, http.StatusUnauthorized},
+		{http.MethodGet, "/recipients/" + aliceReg.ID, "", nil, http.StatusUnauthorized},
+		{http.MethodGet, "/recipients/" + aliceReg.ID + "/matches?limit=0", aliceReg.Token, nil, http.StatusBadRequest},
+		{http.MethodGet, "/recipients/" + aliceReg.ID + "/matches?cursor=-1", aliceReg.Token, nil, http.StatusBadRequest},
+	} {
+		if err := c.as(bad.token).do(bad.method, bad.path, bad.body, bad.status, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := c.as(aliceReg.Token).matches(aliceReg.ID, MaxLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 {
+		t.Fatal("rejected announcements changed the matches")
+	}
+}
+
+// TestConcurrent registers recipients while announcements are added, so
+// scans outside the lock race with merges, and checks that every recipient
+// ends up with all of its payments.
+func TestConcurrent(t *testing.T) {
+	p := protocol.DKSAPSecp256k1()
+	recipients := make([]protocol.Recipient, 4)
+	for i := range recipients {
+		var err error
+		if recipients[i], err = p.GenerateRecipient(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Batch b pays recipient b%4 twice among random announcements.
+	batches := make([][]annfile.Record, 16)
+	for b := range batches {
+		for i := 0; i < 8; i++ {
+			ann, err := p.RandomAnnouncement()
+			if i%4 == 0 {
+				ann, err = p.Send(recipients[b%len(recipients)].MetaAddress())
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			batches[b] = append(batches[b], annfile.NewRecord(p, ann))
+		}
+	}
+
+	s := New()
+	regs := make([]Registration, len(recipients))
+	var wg sync.WaitGroup
+	errs := make(chan error, len(batches)+len(recipients))
+	for _, batch := range batches {
+		wg.Add(1)
+		go func(batch []annfile.Record) {
+			defer wg.Done()
+			if _, err := s.Add(batch); err != nil {
+				errs <- err
+			}
+		}(batch)
+	}
+	for i, r := range recipients {
+		wg.Add(1)
+		go func(i int, r protocol.Recipient) {
+			defer wg.Done()
+			var err error
+			if regs[i], _, err = s.Register(delegation.Export(p, r)); err != nil {
+				errs <- err
+			}
+		}(i, r)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for i, reg := range regs {
+		page, err := s.Matches(reg.ID, reg.Token, 0, MaxLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 2 * len(batches) / len(recipients); len(page.Matches) != want {
+			t.Fatalf("recipient %d: got %d matches, expected %d", i, len(page.Matches), want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sap-go/dataset"
+	"sap-go/protocol"
+)
+
+// Store is a read-only view of a dataset file whose announcement records are
+// accessed in place. On platforms with mmap the file is memory-mapped, so
+// opening a store costs no decoding and the records are paged in on demand.
+type Store struct {
+	dataset.Header
+	layout  dataset.Layout
+	data    []byte
+	records []byte
+	unmap   func([]byte) error
+}
+
+// Open maps the dataset file at path.
+func Open(path string) (*Store, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	data, unmap, err := mapFile(file, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("error mapping %s: %w", path, err)
+	}
+	s, err := newStore(data, unmap)
+	if err != nil {
+		unmap(data)
+		return nil, err
+	}
+	return s, nil
+}
+
+func newStore(data []byte, unmap func([]byte) error) (*Store, error) {
+	header, layout, err := dataset.ReadHeader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	size := int64(layout.Count) * int64(layout.RecordSize())
+	if int64(len(data))-layout.HeaderSize != size {
+		return nil, fmt.Errorf("%w: %d bytes of records, expected %d", dataset.ErrFormat, int64(len(data))-layout.HeaderSize, size)
+	}
+	return &Store{Header: header, layout: layout, data: data, records: data[layout.HeaderSize:], unmap: unmap}, nil
+}
+
+// Close unmaps the file. Announcements returned by the store must not be used afterwards.
+func (s *Store) Close() error {
+	if s.data == nil {
+		return nil
+	}
+	err := s.unmap(s.data)
+	s.data, s.records = nil, nil
+	return err
+}
+
+// Len returns the number of announcements in the store.
+func (s *Store) Len() int {
+	return s.layout.Count
+}
+
+// Announcement returns announcement i without copying; its ephemeral key
+// points into the mapped file.
+func (s *Store) Announcement(i int) protocol.Announcement {
+	size := s.layout.RecordSize()
+	return s.layout.Record(s.records[i*size : (i+1)*size])
+}
+
+// Source returns a scanner source that decompresses ephemeral keys of p on demand.
+func (s *Store) Source(p protocol.Protocol) (*Source, error) {
+	if p.Name() != s.Header.Protocol || p.Curve() != s.Curve {
+		return nil, fmt.Errorf("store is for %s/%s, not %s", s.Header.Protocol, s.Curve, protocol.ID(p))
+	}
+	return &Source{store: s, protocol: p}, nil
+}
+
+// Recipient restores the recipient the owned announcements are addressed to.
+func (s *Store) Recipient(p protocol.Protocol) (protocol.Recipient, error) {
+	if _, err := s.Source(p); err != nil {
+		return nil, err
+	}
+	return p.NewRecipient(s.SpendingKey, s.ViewingKey)
+}
+
+// Source decodes the announcements of a Store lazily. It is safe for
+// concurrent use, so ScanParallel workers decompress points in parallel.
+type Source struct {
+	store    *Store
+	protocol protocol.Protocol
+}
+
+func (s *Source) Len() int { return s.store.Len() }
+
+func (s *Source) Decoded(i int) (protocol.Decoded, error) {
+	return protocol.Decode(s.protocol, s.store.Announcement(i))
+}
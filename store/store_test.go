@@ -0,0 +1,115 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sap-go/dataset"
+	"sap-go/protocol"
+	"testing"
+)
+
+// write generates a dataset of p with every third announcement owned and
+// writes it to a temporary file.
+func write(t *testing.T, p protocol.Protocol) (string, []byte) {
+	r, err := dataset.GenerateRecipient(p, 1)
+	if err != nil {
+		t.Fatalf("GenerateRecipient: %v", err)
+	}
+	owned := make([]bool, 9)
+	for i := range owned {
+		owned[i] = i%3 == 1
+	}
+	d, err := dataset.Generate(p, r, owned, 1)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := dataset.Write(&buf, d); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "dataset.bin")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path, buf.Bytes()
+}
+
+// TestStore opens the dataset of every protocol and checks that the lazily
+// decoded announcements match dataset.Read of the same file.
+func TestStore(t *testing.T) {
+	for _, p := range protocol.All() {
+		t.Run(protocol.ID(p), func(t *testing.T) { testStore(t, p) })
+	}
+}
+
+func testStore(t *testing.T, p protocol.Protocol) {
+	path, file := write(t, p)
+	d, err := dataset.Read(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+	if !reflect.DeepEqual(s.Header, d.Header) || s.Len() != len(d.Announcements) {
+		t.Fatalf("Open: got header %+v with %d announcements, expected %+v with %d", s.Header, s.Len(), d.Header, len(d.Announcements))
+	}
+
+	src, err := s.Source(p)
+	if err != nil {
+		t.Fatalf("Source: %v", err)
+	}
+	for i, ann := range d.Announcements {
+		expected, err := protocol.Decode(p, ann)
+		if err != nil {
+			t.Fatalf("Decode %d: %v", i, err)
+		}
+		got, err := src.Decoded(i)
+		if err != nil {
+			t.Fatalf("Decoded %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Decoded %d: got %+v, expected %+v", i, got, expected)
+		}
+	}
+	if _, err := s.Recipient(p); err != nil {
+		t.Errorf("Recipient: %v", err)
+	}
+
+	for _, other := range protocol.All() {
+		if protocol.ID(other) == protocol.ID(p) {
+			continue
+		}
+		if _, err := s.Source(other); err == nil {
+			t.Errorf("Source(%s): expected an error for a %s store", protocol.ID(other), protocol.ID(p))
+		}
+		if _, err := s.Recipient(other); err == nil {
+			t.Errorf("Recipient(%s): expected an error for a %s store", protocol.ID(other), protocol.ID(p))
+		}
+	}
+}
+
+// TestSize checks that a file whose size disagrees with its header is rejected.
+func TestSize(t *testing.T) {
+	p := protocol.All()[0]
+	path, file := write(t, p)
+	for name, b := range map[string][]byte{
+		"truncated": file[:len(file)-1],
+		"trailing":  append(bytes.Clone(file), 0),
+	} {
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := Open(path); !errors.Is(err, dataset.ErrFormat) {
+			if err == nil {
+				s.Close()
+			}
+			t.Errorf("Open: %s: got %v, expected %v", name, err, dataset.ErrFormat)
+		}
+	}
+}
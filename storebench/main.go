@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"runtime"
+	"sap-go/bench"
+	"sap-go/dataset"
+	"sap-go/harness"
+	"sap-go/protocol"
+	"sap-go/scanner"
+	"sap-go/store"
+	"time"
+)
+
+// measurement is the cost of scanning a dataset in one storage mode.
+type measurement struct {
+	mode    string
+	load    time.Duration
+	scan    time.Duration
+	heap    uint64
+	matches int
+	owned   int
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapInuse
+}
+
+// ensureDataset generates the dataset at path unless it already exists.
+func ensureDataset(p protocol.Protocol, path string, n int, fraction float64) error {
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	fmt.Printf("Generating %d announcements for %s\n", n, protocol.ID(p))
+	opts := &bench.Options{Announcements: n, Owned: int(math.Round(fraction * float64(n)))}
+	d, err := harness.GenerateDataset(p, opts)
+	if err != nil {
+		return err
+	}
+	return dataset.Save(path, d)
+}
+
+// scanInMemory loads the whole dataset, decodes every ephemeral key and scans the decoded slice.
+func scanInMemory(p protocol.Protocol, path string, workers int, viewTags bool) (measurement, error) {
+	m := measurement{mode: "in-memory"}
+	baseline := heapInUse()
+
+	startTime := time.Now()
+	d, err := dataset.Load(path)
+	if err != nil {
+		return m, err
+	}
+	fixture, err := harness.FixtureFromDataset(p, d)
+	if err != nil {
+		return m, err
+	}
+	m.load = time.Since(startTime)
+	m.heap = heapInUse() - min(baseline, heapInUse())
+
+	startTime = time.Now()
+	_, stats, err := scanner.ScanParallel(p, fixture.Recipient, scanner.Slice(fixture.Announcements), workers, viewTags)
+	m.scan = time.Since(startTime)
+	m.matches, m.owned = stats.Matches, fixture.Owned
+	runtime.KeepAlive(d)
+	return m, err
+}
+
+// scanMapped maps the dataset and decompresses ephemeral keys inside the scan workers.
+func scanMapped(p protocol.Protocol, path string, workers int, viewTags bool) (measurement, error) {
+	m := measurement{mode: "mmap"}
+	baseline := heapInUse()
+
+	startTime := time.Now()
+	s, err := store.Open(path)
+	if err != nil {
+		return m, err
+	}
+	defer s.Close()
+	src, err := s.Source(p)
+	if err != nil {
+		return m, err
+	}
+	recipient, err := s.Recipient(p)
+	if err != nil {
+		return m, err
+	}
+	m.load = time.Since(startTime)
+	m.heap = heapInUse() - min(baseline, heapInUse())
+
+	startTime = time.Now()
+	_, stats, err := scanner.ScanParallel(p, recipient, src, workers, viewTags)
+	m.scan = time.Since(startTime)
+	m.matches, m.owned = stats.Matches, s.Owned
+	return m, err
+}
+
+func main() {
+	protocolName := flag.String("protocol", "ecpdksap", "protocol to benchmark")
+	curveName := flag.String("curve", "bn254", "curve the protocol is instantiated on")
+	n := flag.Int("n", 1000000, "number of announcements")
+	fraction := flag.Float64("fraction", 0.00001, "fraction of announcements addressed to the recipient")
+	path := flag.String("dataset", "", "dataset file, generated if missing (default dataset_<protocol>_<curve>_<n>.sap)")
+	workers := flag.Int("workers", runtime.NumCPU(), "number of scanning goroutines")
+	viewTags := flag.Bool("viewtags", true, "filter announcements by view tag")
+	flag.Parse()
+
+	p, err := protocol.Lookup(*protocolName, *curveName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if *path == "" {
+		*path = dataset.FileName(p.Name(), p.Curve(), *n)
+	}
+	if err := ensureDataset(p, *path, *n, *fraction); err != nil {
+		fmt.Println("Error preparing dataset:", err)
+		os.Exit(1)
+	}
+
+	var measurements []measurement
+	for _, scan := range []func(protocol.Protocol, string, int, bool) (measurement, error){scanInMemory, scanMapped} {
+		m, err := scan(p, *path, *workers, *viewTags)
+		if err != nil {
+			fmt.Println("Error scanning dataset:", err)
+			os.Exit(1)
+		}
+		measurements = append(measurements, m)
+	}
+
+	fmt.Printf("%s, %s, %d workers\n", protocol.ID(p), *path, *workers)
+	fmt.Printf("%-10s %12s %12s %12s %10s %8s\n", "Mode", "Load", "Scan", "Total", "Heap (MB)", "Matches")
+	for _, m := range measurements {
+		fmt.Printf("%-10s %12v %12v %12v %10.1f %4d/%-3d\n", m.mode, m.load.Round(time.Millisecond), m.scan.Round(time.Millisecond),
+			(m.load + m.scan).Round(time.Millisecond), float64(m.heap)/(1<<20), m.matches, m.owned)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sap-go/delegation"
+	"sap-go/erc5564"
+	"sap-go/hdkey"
+	"sap-go/keystore"
+	"sap-go/mnemonic"
+	"sap-go/protocol"
+	"strings"
+)
+
+const usage = `Usage:
+  wallet new [flags]       create a mnemonic and the recipient it derives
+  wallet restore [flags]   restore a recipient from a mnemonic read from stdin
+
+Run "wallet <command> -h" for the flags of a command.`
+
+// options are the flags shared by both commands.
+type options struct {
+	protocolName, curveName string
+	account                 uint
+	passphraseFile          string
+	keystorePath            string
+	passwordFile            string
+	kdf                     string
+	viewOnly                bool
+	credentialPath          string
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.protocolName, "protocol", "ecpdksap", "protocol to derive keys for")
+	fs.StringVar(&o.curveName, "curve", "bn254", "curve the protocol is instantiated on")
+	fs.UintVar(&o.account, "account", 0, "account index")
+	fs.StringVar(&o.passphraseFile, "passphrase-file", "", "file holding the optional BIP-39 passphrase")
+	fs.StringVar(&o.keystorePath, "keystore", "", "write the keys to an encrypted keystore at this path")
+	fs.StringVar(&o.passwordFile, "password-file", "", "file holding the keystore password")
+	fs.StringVar(&o.kdf, "kdf", keystore.KDFScrypt, "keystore KDF: scrypt or argon2id")
+	fs.BoolVar(&o.viewOnly, "view-only", false, "write a view-only keystore without the spending key")
+	fs.StringVar(&o.credentialPath, "credential", "", "write a viewing credential for a third-party scanner to this path")
+}
+
+// recipient derives the recipient of the selected account from mnemonic.
+func (o *options) recipient(phrase string) (protocol.Protocol, protocol.Recipient, error) {
+	p, err := protocol.Lookup(o.protocolName, o.curveName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.account > 1<<31-1 {
+		return nil, nil, fmt.Errorf("account %d out of range", o.account)
+	}
+	var passphrase []byte
+	if o.passphraseFile != "" {
+		if passphrase, err = readSecret(o.passphraseFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	seed, err := mnemonic.Seed(phrase, string(passphrase))
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := hdkey.Recipient(p, seed, uint32(o.account))
+	return p, r, err
+}
+
+// readSecret reads a password or passphrase from path, without the trailing
+// newline. Secrets are read from files so they stay out of the shell history
+// and the process list.
+func readSecret(path string) ([]byte, error) {
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(strings.TrimRight(string(secret), "\r\n")), nil
+}
+
+// writeKeystore encrypts r into the keystore file, if one was requested.
+func (o *options) writeKeystore(p protocol.Protocol, r protocol.Recipient) error {
+	if o.keystorePath == "" {
+		return nil
+	}
+	if o.passwordFile == "" {
+		return errors.New("-keystore requires -password-file")
+	}
+	password, err := readSecret(o.passwordFile)
+	if err != nil {
+		return err
+	}
+	if len(password) == 0 {
+		return errors.New("empty keystore password")
+	}
+	kdf, err := keystore.ParseKDF(o.kdf)
+	if err != nil {
+		return err
+	}
+	encrypt := keystore.Encrypt
+	if o.viewOnly {
+		encrypt = keystore.EncryptViewOnly
+	}
+	ks, err := encrypt(p, r, password, kdf)
+	if err != nil {
+		return err
+	}
+	if err := keystore.Save(o.keystorePath, ks); err != nil {
+		return err
+	}
+	fmt.Println("Keystore:", o.keystorePath)
+	return nil
+}
+
+// writeCredential exports the viewing credential of r, if one was requested.
+func (o *options) writeCredential(p protocol.Protocol, r protocol.Recipient) error {
+	if o.credentialPath == "" {
+		return nil
+	}
+	if err := delegation.Save(o.credentialPath, delegation.Export(p, r)); err != nil {
+		return err
+	}
+	fmt.Println("Viewing credential:", o.credentialPath)
+	return nil
+}
+
+// save writes the requested keystore and viewing credential.
+func (o *options) save(p protocol.Protocol, r protocol.Recipient) error {
+	if err := o.writeKeystore(p, r); err != nil {
+		return err
+	}
+	return o.writeCredential(p, r)
+}
+
+func (o *options) print(p protocol.Protocol, r protocol.Recipient) {
+	spendPath, _ := hdkey.Path(p, uint32(o.account), hdkey.Spending)
+	viewPath, _ := hdkey.Path(p, uint32(o.account), hdkey.Viewing)
+	meta := r.MetaAddress()
+	fmt.Println("Protocol:", protocol.ID(p))
+	fmt.Printf("Account: %d (spending %s, viewing %s)\n", o.account, hdkey.FormatPath(spendPath), hdkey.FormatPath(viewPath))
+	fmt.Println("Spending public key:", hex.EncodeToString(meta.Spend))
+	fmt.Println("Viewing public key: ", hex.EncodeToString(meta.View))
+	fmt.Println("Meta-address:", erc5564.FormatMetaAddress("eth", meta))
+}
+
+func newWallet(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	var o options
+	o.register(fs)
+	words := fs.Int("words", 24, "number of mnemonic words: 12, 15, 18, 21 or 24")
+	fs.Parse(args)
+
+	phrase, err := mnemonic.Generate(*words * 32 / 3)
+	if err != nil {
+		return err
+	}
+	p, r, err := o.recipient(phrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Mnemonic:", phrase)
+	o.print(p, r)
+	return o.save(p, r)
+}
+
+func restoreWallet(args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	var o options
+	o.register(fs)
+	fs.Parse(args)
+
+	phrase, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && phrase == "" {
+		return fmt.Errorf("error reading mnemonic from stdin: %w", err)
+	}
+	p, r, err := o.recipient(phrase)
+	if err != nil {
+		return err
+	}
+	o.print(p, r)
+	return o.save(p, r)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	var err error
+	switch os.Args[1] {
+	case "new":
+		err = newWallet(os.Args[2:])
+	case "restore":
+		err = restoreWallet(os.Args[2:])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}